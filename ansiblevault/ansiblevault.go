@@ -0,0 +1,309 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ansiblevault implements the Ansible Vault file format in the versions 1.1 and 1.2 with the cipher AES256.
+//
+// A vault consists of a header line "$ANSIBLE_VAULT;1.1;AES256" or "$ANSIBLE_VAULT;1.2;AES256;<vault id>",
+// followed by hex encoded lines of at most 80 characters.
+// The hex encoded data consist of the hex encoded salt, HMAC and ciphertext, separated by line feeds.
+//
+// Encryption key, HMAC key and initialization vector are derived from the password and a 32 byte salt
+// with PBKDF2-HMAC-SHA256 and 10000 iterations.
+// The data are PKCS#7 padded and encrypted with AES-256 in CTR mode.
+// The HMAC-SHA256 is calculated over the ciphertext.
+package ansiblevault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/pbkdf2"
+	"strings"
+)
+
+// ******** Public types ********
+
+// Crypter encrypts and decrypts vaults with one password.
+//
+// A Crypter is safe for concurrent use by multiple goroutines, as it is used read-only.
+type Crypter struct {
+	password []byte
+	padder   *blockpad.BlockPad
+}
+
+// Header contains the fields of the header line of a vault.
+type Header struct {
+	// Version is the format version, i.e. "1.1" or "1.2".
+	Version string
+
+	// Cipher is the name of the cipher, i.e. "AES256".
+	Cipher string
+
+	// VaultID is the vault id of a version 1.2 vault. It is empty for version 1.1.
+	VaultID string
+}
+
+// ******** Public errors ********
+
+var (
+	// ErrNotVault means that the data do not start with the vault header.
+	ErrNotVault = errors.New(`data are not an Ansible vault`)
+
+	// ErrUnsupportedVersion means that the format version of the vault is not supported.
+	ErrUnsupportedVersion = errors.New(`unsupported vault version`)
+
+	// ErrUnsupportedCipher means that the cipher of the vault is not supported.
+	ErrUnsupportedCipher = errors.New(`unsupported vault cipher`)
+
+	// ErrInvalidFormat means that the vault data are not correctly encoded.
+	ErrInvalidFormat = errors.New(`invalid vault format`)
+
+	// ErrInvalidVaultID means that a vault id contains characters that are not allowed in the header.
+	ErrInvalidVaultID = errors.New(`invalid vault id`)
+
+	// ErrAuthenticationFailed means that the HMAC does not match, i.e. the password is wrong or the vault has been modified.
+	ErrAuthenticationFailed = errors.New(`vault authentication failed`)
+)
+
+// ******** Private constants ********
+
+// magic is the first field of the header line.
+const magic = `$ANSIBLE_VAULT`
+
+// These are the supported versions and the supported cipher.
+const (
+	version11    = `1.1`
+	version12    = `1.2`
+	cipherAES256 = `AES256`
+)
+
+// These are the parameters of the key derivation.
+const (
+	saltSize    = 32
+	keySize     = 32
+	iterations  = 10000
+	derivedSize = keySize + keySize + aes.BlockSize
+)
+
+// lineLen is the maximum length of a hex encoded line.
+const lineLen = 80
+
+// ******** Public creation function ********
+
+// NewCrypter creates a Crypter for a vault password.
+func NewCrypter(password []byte) (*Crypter, error) {
+	padder, err := blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Crypter{
+		password: append([]byte(nil), password...),
+		padder:   padder,
+	}, nil
+}
+
+// ******** Public functions ********
+
+// IsVault checks whether data start with the vault header.
+func IsVault(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(magic+`;`))
+}
+
+// ParseHeader parses the header line of a vault.
+// This can be used to select the password by the vault id.
+func ParseHeader(vault []byte) (*Header, error) {
+	headerLine, _, _ := bytes.Cut(bytes.TrimSpace(vault), []byte("\n"))
+
+	fields := strings.Split(strings.TrimSpace(string(headerLine)), `;`)
+	if len(fields) < 3 || len(fields) > 4 || fields[0] != magic {
+		return nil, ErrNotVault
+	}
+
+	result := &Header{
+		Version: strings.TrimSpace(fields[1]),
+		Cipher:  strings.TrimSpace(fields[2]),
+	}
+
+	if len(fields) == 4 {
+		result.VaultID = strings.TrimSpace(fields[3])
+	}
+
+	return result, nil
+}
+
+// Encrypt encrypts data with a random salt.
+// If vaultID is empty, a version 1.1 vault is created, otherwise a version 1.2 vault with this vault id.
+func (c *Crypter) Encrypt(data []byte, vaultID string) ([]byte, error) {
+	if strings.ContainsAny(vaultID, ";\r\n") {
+		return nil, ErrInvalidVaultID
+	}
+
+	salt := make([]byte, saltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.encrypt(data, vaultID, salt), nil
+}
+
+// Decrypt checks the HMAC of a vault and decrypts it.
+func (c *Crypter) Decrypt(vault []byte) ([]byte, error) {
+	header, err := ParseHeader(vault)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Version != version11 && header.Version != version12 {
+		return nil, ErrUnsupportedVersion
+	}
+
+	if header.Cipher != cipherAES256 {
+		return nil, ErrUnsupportedCipher
+	}
+
+	// 1. Decode the envelope.
+	var salt, mac, ciphertext []byte
+	salt, mac, ciphertext, err = decodeBody(vault)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Check the HMAC.
+	cipherKey, macKey, iv := c.deriveKeys(salt)
+	if !hmac.Equal(mac, calculateMAC(macKey, ciphertext)) {
+		return nil, ErrAuthenticationFailed
+	}
+
+	// 3. Decrypt and unpad.
+	result := make([]byte, len(ciphertext))
+	newCTR(cipherKey, iv).XORKeyStream(result, ciphertext)
+
+	return c.padder.Unpad(result)
+}
+
+// ******** Private functions ********
+
+// encrypt encrypts data with a given salt.
+func (c *Crypter) encrypt(data []byte, vaultID string, salt []byte) []byte {
+	cipherKey, macKey, iv := c.deriveKeys(salt)
+
+	// 1. Pad and encrypt.
+	ciphertext := c.padder.Pad(data)
+	newCTR(cipherKey, iv).XORKeyStream(ciphertext, ciphertext)
+
+	// 2. Build the envelope.
+	mac := calculateMAC(macKey, ciphertext)
+	body := strings.Join([]string{
+		hex.EncodeToString(salt),
+		hex.EncodeToString(mac),
+		hex.EncodeToString(ciphertext),
+	}, "\n")
+
+	return formatVault(hex.EncodeToString([]byte(body)), vaultID)
+}
+
+// deriveKeys derives the encryption key, the HMAC key and the initialization vector from the password and the salt.
+func (c *Crypter) deriveKeys(salt []byte) ([]byte, []byte, []byte) {
+	derived := pbkdf2.Key(c.password, salt, iterations, derivedSize, sha256.New)
+
+	return derived[:keySize], derived[keySize : keySize+keySize], derived[keySize+keySize:]
+}
+
+// formatVault builds the header line and splits the hex encoded body into lines.
+func formatVault(encodedBody string, vaultID string) []byte {
+	var sb strings.Builder
+	sb.Grow(len(magic) + len(vaultID) + 20 + len(encodedBody) + len(encodedBody)/lineLen + 1)
+
+	sb.WriteString(magic)
+	if len(vaultID) == 0 {
+		sb.WriteString(`;` + version11 + `;` + cipherAES256)
+	} else {
+		sb.WriteString(`;` + version12 + `;` + cipherAES256 + `;` + vaultID)
+	}
+	sb.WriteByte('\n')
+
+	for len(encodedBody) > 0 {
+		n := min(lineLen, len(encodedBody))
+		sb.WriteString(encodedBody[:n])
+		sb.WriteByte('\n')
+		encodedBody = encodedBody[n:]
+	}
+
+	return []byte(sb.String())
+}
+
+// decodeBody decodes the hex encoded lines after the header line into salt, HMAC and ciphertext.
+func decodeBody(vault []byte) ([]byte, []byte, []byte, error) {
+	lines := bytes.Split(bytes.TrimSpace(vault), []byte("\n"))
+
+	var encodedBody []byte
+	for _, line := range lines[1:] {
+		encodedBody = append(encodedBody, bytes.TrimSpace(line)...)
+	}
+
+	body := make([]byte, hex.DecodedLen(len(encodedBody)))
+	_, err := hex.Decode(body, encodedBody)
+	if err != nil {
+		return nil, nil, nil, ErrInvalidFormat
+	}
+
+	parts := bytes.SplitN(body, []byte("\n"), 3)
+	if len(parts) != 3 {
+		return nil, nil, nil, ErrInvalidFormat
+	}
+
+	var result [3][]byte
+	for i, part := range parts {
+		result[i], err = hex.DecodeString(string(part))
+		if err != nil {
+			return nil, nil, nil, ErrInvalidFormat
+		}
+	}
+
+	if len(result[1]) != sha256.Size {
+		return nil, nil, nil, ErrInvalidFormat
+	}
+
+	return result[0], result[1], result[2], nil
+}
+
+// calculateMAC calculates the HMAC-SHA256 of the ciphertext.
+func calculateMAC(macKey []byte, ciphertext []byte) []byte {
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(ciphertext)
+
+	return mac.Sum(nil)
+}
+
+// newCTR creates the AES-256 CTR stream.
+func newCTR(cipherKey []byte, iv []byte) cipher.Stream {
+	// The key always has a valid size, so there can be no error.
+	block, _ := aes.NewCipher(cipherKey)
+
+	return cipher.NewCTR(block, iv)
+}
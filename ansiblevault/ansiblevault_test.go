@@ -0,0 +1,212 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ansiblevault
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the number of data lengths in the functional tests.
+const loopCount = 100
+
+// These are the inputs of the fixtures.
+const (
+	fixturePassword = `ansible secret`
+	fixtureSalt     = `202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f`
+	fixtureData     = "db_password: hunter2\n"
+)
+
+// fixtureBody is the encrypted fixture data in the format of "ansible-vault encrypt".
+// It has been created with the Python functions hashlib.pbkdf2_hmac and hmac.new
+// and "openssl enc -aes-256-ctr -nopad" on the PKCS#7 padded fixture data.
+const fixtureBody = `32303231323232333234323532363237323832393261326232633264326532663330333133323333
+3334333533363337333833393361336233633364336533660a393838343933306265663435626366
+31316635336430643536373832316539653033396564393034396430663664656636613061313861
+3364363762306664350a636238653635336331373938616137636339623332373166383964613831
+65636139643235383731353663646139363965646661306566663738623430303235
+`
+
+// These are the fixtures in the versions 1.1 and 1.2.
+const (
+	fixture11 = "$ANSIBLE_VAULT;1.1;AES256\n" + fixtureBody
+	fixture12 = "$ANSIBLE_VAULT;1.2;AES256;prod\n" + fixtureBody
+)
+
+// ******** Fixture tests ********
+
+func TestDecryptFixtures(t *testing.T) {
+	c := makeCrypter(t, fixturePassword)
+
+	for _, fixture := range []string{fixture11, fixture12} {
+		data, err := c.Decrypt([]byte(fixture))
+		if err != nil {
+			t.Fatalf(`Decryption failed: %v`, err)
+		}
+		if string(data) != fixtureData {
+			t.Fatalf(`Decrypted data '%s' differ from fixture data`, data)
+		}
+	}
+}
+
+func TestEncryptFixtures(t *testing.T) {
+	c := makeCrypter(t, fixturePassword)
+	salt, _ := hex.DecodeString(fixtureSalt)
+
+	vault := c.encrypt([]byte(fixtureData), ``, salt)
+	if string(vault) != fixture11 {
+		t.Fatalf("Encrypted vault differs from fixture:\n%s", vault)
+	}
+
+	vault = c.encrypt([]byte(fixtureData), `prod`, salt)
+	if string(vault) != fixture12 {
+		t.Fatalf("Encrypted vault with vault id differs from fixture:\n%s", vault)
+	}
+}
+
+func TestDecryptIndented(t *testing.T) {
+	c := makeCrypter(t, fixturePassword)
+
+	// This is how a vault looks when it is embedded in a YAML file with "!vault |".
+	indented := "  " + strings.ReplaceAll(strings.TrimSpace(fixture12), "\n", "\n  ")
+
+	data, err := c.Decrypt([]byte(indented))
+	if err != nil {
+		t.Fatalf(`Decryption of indented vault failed: %v`, err)
+	}
+	if string(data) != fixtureData {
+		t.Fatalf(`Decrypted data '%s' differ from fixture data`, data)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	header, err := ParseHeader([]byte(fixture12))
+	if err != nil {
+		t.Fatalf(`Parsing header failed: %v`, err)
+	}
+	if header.Version != `1.2` || header.Cipher != `AES256` || header.VaultID != `prod` {
+		t.Fatalf(`Wrong header: %+v`, header)
+	}
+
+	if !IsVault([]byte(fixture11)) {
+		t.Fatal(`Vault was not recognized`)
+	}
+
+	if IsVault([]byte(fixtureData)) {
+		t.Fatal(`Clear data were recognized as vault`)
+	}
+}
+
+// ******** Functional tests ********
+
+func TestEncryptDecrypt(t *testing.T) {
+	c := makeCrypter(t, fixturePassword)
+
+	for dataLen := 0; dataLen <= loopCount; dataLen += 7 {
+		data := bytes.Repeat([]byte{'v'}, dataLen)
+
+		vault, err := c.Encrypt(data, `dev`)
+		if err != nil {
+			t.Fatalf(`Encryption of %d bytes failed: %v`, dataLen, err)
+		}
+
+		for _, line := range strings.Split(string(vault), "\n") {
+			if len(line) > 80 {
+				t.Fatalf(`Line of %d characters is too long`, len(line))
+			}
+		}
+
+		var decrypted []byte
+		decrypted, err = c.Decrypt(vault)
+		if err != nil {
+			t.Fatalf(`Decryption of %d bytes failed: %v`, dataLen, err)
+		}
+		if !bytes.Equal(decrypted, data) {
+			t.Fatalf(`Decrypted data of %d bytes differ from data`, dataLen)
+		}
+	}
+}
+
+// ******** Error tests ********
+
+func TestWrongPassword(t *testing.T) {
+	c := makeCrypter(t, `wrong password`)
+
+	_, err := c.Decrypt([]byte(fixture11))
+	if !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf(`Wrong password was not detected: %v`, err)
+	}
+}
+
+func TestModifiedVault(t *testing.T) {
+	c := makeCrypter(t, fixturePassword)
+
+	// Change the last hex encoded ciphertext character from '5' to '6'.
+	modified := []byte(fixture11)
+	modified[len(modified)-2] = '6'
+
+	_, err := c.Decrypt(modified)
+	if !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf(`Modified vault was not detected: %v`, err)
+	}
+}
+
+func TestInvalidVaults(t *testing.T) {
+	c := makeCrypter(t, fixturePassword)
+
+	for _, tc := range []struct {
+		vault string
+		err   error
+	}{
+		{vault: fixtureData, err: ErrNotVault},
+		{vault: "$ANSIBLE_VAULT;1.0;AES\n" + fixtureBody, err: ErrUnsupportedVersion},
+		{vault: "$ANSIBLE_VAULT;1.1;AES128\n" + fixtureBody, err: ErrUnsupportedCipher},
+		{vault: "$ANSIBLE_VAULT;1.1;AES256\nxyz\n", err: ErrInvalidFormat},
+		{vault: "$ANSIBLE_VAULT;1.1;AES256\n" + hex.EncodeToString([]byte("00\n11")), err: ErrInvalidFormat},
+	} {
+		_, err := c.Decrypt([]byte(tc.vault))
+		if !errors.Is(err, tc.err) {
+			t.Fatalf(`Wrong error for invalid vault: %v`, err)
+		}
+	}
+
+	_, err := c.Encrypt([]byte(fixtureData), `a;b`)
+	if !errors.Is(err, ErrInvalidVaultID) {
+		t.Fatalf(`Invalid vault id was not detected: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// makeCrypter creates a Crypter for a password.
+func makeCrypter(t *testing.T, password string) *Crypter {
+	c, err := NewCrypter([]byte(password))
+	if err != nil {
+		t.Fatalf(`Could not create crypter: %v`, err)
+	}
+
+	return c
+}
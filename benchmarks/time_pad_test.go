@@ -88,6 +88,26 @@ func BenchmarkPadNotLastByteShort(b *testing.B) {
 	doBenchPad(b, blockpad.ArbitraryTailByte, testBlockSize-1)
 }
 
+func BenchmarkPadMcryptZeroLong(b *testing.B) {
+	b.StopTimer()
+	doBenchPad(b, blockpad.McryptZero, 1)
+}
+
+func BenchmarkPadMcryptZeroShort(b *testing.B) {
+	b.StopTimer()
+	doBenchPad(b, blockpad.McryptZero, testBlockSize-1)
+}
+
+func BenchmarkPadISO97971M3Long(b *testing.B) {
+	b.StopTimer()
+	doBenchPad(b, blockpad.ISO97971M3, 1)
+}
+
+func BenchmarkPadISO97971M3Short(b *testing.B) {
+	b.StopTimer()
+	doBenchPad(b, blockpad.ISO97971M3, testBlockSize-1)
+}
+
 // ******** Private function ********
 
 // doBenchPad runs a Pad benchmark with the given parameters.
@@ -86,6 +86,26 @@ func BenchmarkPadLastBlockNotLastByteShort(b *testing.B) {
 	doBenchPadLastBlock(b, blockpad.NotLastByte, testBlockSize-1)
 }
 
+func BenchmarkPadLastBlockMcryptZeroLong(b *testing.B) {
+	b.StopTimer()
+	doBenchPadLastBlock(b, blockpad.McryptZero, 1)
+}
+
+func BenchmarkPadLastBlockMcryptZeroShort(b *testing.B) {
+	b.StopTimer()
+	doBenchPadLastBlock(b, blockpad.McryptZero, testBlockSize-1)
+}
+
+func BenchmarkPadLastBlockISO97971M3Long(b *testing.B) {
+	b.StopTimer()
+	doBenchPadLastBlock(b, blockpad.ISO97971M3, 1)
+}
+
+func BenchmarkPadLastBlockISO97971M3Short(b *testing.B) {
+	b.StopTimer()
+	doBenchPadLastBlock(b, blockpad.ISO97971M3, testBlockSize-1)
+}
+
 // ******** Private function ********
 
 // doBenchPadLastBlock runs a PadLastBlock benchmark with the given parameters.
@@ -84,6 +84,26 @@ func BenchmarkUnpadNotLastByteShort(b *testing.B) {
 	doUnpad(b, blockpad.ArbitraryTailByte, testBlockSize-1)
 }
 
+func BenchmarkUnpadMcryptZeroLong(b *testing.B) {
+	b.StopTimer()
+	doUnpad(b, blockpad.McryptZero, 1)
+}
+
+func BenchmarkUnpadMcryptZeroShort(b *testing.B) {
+	b.StopTimer()
+	doUnpad(b, blockpad.McryptZero, testBlockSize-1)
+}
+
+func BenchmarkUnpadISO97971M3Long(b *testing.B) {
+	b.StopTimer()
+	doUnpad(b, blockpad.ISO97971M3, 1)
+}
+
+func BenchmarkUnpadISO97971M3Short(b *testing.B) {
+	b.StopTimer()
+	doUnpad(b, blockpad.ISO97971M3, testBlockSize-1)
+}
+
 // ******** Private function ********
 
 func doUnpad(b *testing.B, padAlgorithm blockpad.PadAlgorithm, unpaddedDataLen int) {
@@ -0,0 +1,57 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package blockmode implements the block modes ECB, PCBC and IGE as [crypto/cipher/BlockMode].
+//
+// The standard library only implements the CBC mode.
+// These block modes can be used with paddings from [github.com/xformerfhs/blockpad.NewBlockPadding],
+// e.g. with the package [github.com/xformerfhs/blockpad/padmode].
+//
+// ECB encrypts all blocks independently and therefore leaks equal plaintext blocks.
+// It is only meant for compatibility with systems that use ECB mode.
+// None of these modes provides integrity protection.
+package blockmode
+
+// ******** Private functions ********
+
+// checkBlocks panics if src does not consist of full blocks or if dst is smaller than src.
+func checkBlocks(dst []byte, src []byte, blockSize int) {
+	if len(src)%blockSize != 0 {
+		panic(`blockmode: input not full blocks`)
+	}
+
+	if len(dst) < len(src) {
+		panic(`blockmode: output smaller than input`)
+	}
+}
+
+// checkIV panics if the initialization vector does not have the expected length.
+func checkIV(iv []byte, ivLen int) {
+	if len(iv) != ivLen {
+		panic(`blockmode: initialization vector has wrong length`)
+	}
+}
+
+// xorBlock XORs a and b into dst.
+func xorBlock(dst []byte, a []byte, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
@@ -0,0 +1,154 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockmode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	"github.com/xformerfhs/blockpad/padmode"
+	mrand "math/rand"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the number of times a functional test is to be performed.
+const loopCount = 100
+
+// ******** Private types ********
+
+// modeFactory creates an encrypter and a decrypter of a block mode.
+type modeFactory struct {
+	name         string
+	newEncrypter func(block cipher.Block, iv []byte) cipher.BlockMode
+	newDecrypter func(block cipher.Block, iv []byte) cipher.BlockMode
+	ivBlocks     int
+}
+
+// ******** Private variables ********
+
+// modeFactories contains all block modes of this package.
+var modeFactories = []modeFactory{
+	{
+		name:         `ECB`,
+		newEncrypter: func(block cipher.Block, _ []byte) cipher.BlockMode { return NewECBEncrypter(block) },
+		newDecrypter: func(block cipher.Block, _ []byte) cipher.BlockMode { return NewECBDecrypter(block) },
+	},
+	{name: `PCBC`, newEncrypter: NewPCBCEncrypter, newDecrypter: NewPCBCDecrypter, ivBlocks: 1},
+	{name: `IGE`, newEncrypter: NewIGEEncrypter, newDecrypter: NewIGEDecrypter, ivBlocks: 2},
+}
+
+// ******** Functional tests ********
+
+func TestPaddedSealOpen(t *testing.T) {
+	block, _ := aes.NewCipher(testhelper.MakeTestSlice(32))
+	padder, err := blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad: %v`, err)
+	}
+
+	for _, mf := range modeFactories {
+		for i := 0; i < loopCount; i++ {
+			iv := testhelper.MakeTestSlice(mf.ivBlocks * aes.BlockSize)
+			data := testhelper.MakeTestSlice(mrand.Intn(200))
+
+			encrypter, _ := padmode.NewPaddedEncrypter(mf.newEncrypter(block, iv), padder)
+			decrypter, _ := padmode.NewPaddedDecrypter(mf.newDecrypter(block, iv), padder)
+
+			ciphertext := encrypter.Seal(nil, data)
+
+			var decrypted []byte
+			decrypted, err = decrypter.Open(nil, ciphertext)
+			if err != nil {
+				t.Fatalf(`%s: Open failed: %v`, mf.name, err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Fatalf(`%s: decrypted data differs from data`, mf.name)
+			}
+		}
+	}
+}
+
+func TestChainingOverCalls(t *testing.T) {
+	block, _ := aes.NewCipher(testhelper.MakeTestSlice(32))
+	data := testhelper.MakeTestSlice(5 * aes.BlockSize)
+
+	for _, mf := range modeFactories {
+		iv := testhelper.MakeTestSlice(mf.ivBlocks * aes.BlockSize)
+
+		expected := make([]byte, len(data))
+		mf.newEncrypter(block, iv).CryptBlocks(expected, data)
+
+		// Encrypting in pieces yields the same result as encrypting in one call.
+		result := make([]byte, len(data))
+		encrypter := mf.newEncrypter(block, iv)
+		encrypter.CryptBlocks(result[:2*aes.BlockSize], data[:2*aes.BlockSize])
+		encrypter.CryptBlocks(result[2*aes.BlockSize:], data[2*aes.BlockSize:])
+		if !bytes.Equal(result, expected) {
+			t.Fatalf(`%s: encryption in pieces differs`, mf.name)
+		}
+
+		// Decrypt in place.
+		mf.newDecrypter(block, iv).CryptBlocks(result, result)
+		if !bytes.Equal(result, data) {
+			t.Fatalf(`%s: decryption in place differs`, mf.name)
+		}
+	}
+}
+
+// ******** Test invalid data ********
+
+func TestPartialBlock(t *testing.T) {
+	block, _ := aes.NewCipher(make([]byte, 16))
+
+	for _, mf := range modeFactories {
+		iv := make([]byte, mf.ivBlocks*aes.BlockSize)
+		expectPanic(t, mf.name+` partial block`, func() {
+			mf.newEncrypter(block, iv).CryptBlocks(make([]byte, 17), make([]byte, 17))
+		})
+		expectPanic(t, mf.name+` short output`, func() {
+			mf.newDecrypter(block, iv).CryptBlocks(make([]byte, 16), make([]byte, 32))
+		})
+	}
+}
+
+func TestWrongIVLength(t *testing.T) {
+	block, _ := aes.NewCipher(make([]byte, 16))
+
+	expectPanic(t, `PCBC`, func() { NewPCBCEncrypter(block, make([]byte, 8)) })
+	expectPanic(t, `IGE`, func() { NewIGEDecrypter(block, make([]byte, 16)) })
+}
+
+// ******** Private functions ********
+
+// expectPanic checks that f panics.
+func expectPanic(t *testing.T, name string, f func()) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf(`%s did not panic`, name)
+		}
+	}()
+
+	f()
+}
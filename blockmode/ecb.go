@@ -0,0 +1,67 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockmode
+
+import "crypto/cipher"
+
+// ******** Private types ********
+
+// ecb implements the ECB mode.
+type ecb struct {
+	block       cipher.Block
+	isDecrypter bool
+}
+
+// ******** Public creation functions ********
+
+// NewECBEncrypter creates an ECB encrypter.
+func NewECBEncrypter(block cipher.Block) cipher.BlockMode {
+	return &ecb{block: block}
+}
+
+// NewECBDecrypter creates an ECB decrypter.
+func NewECBDecrypter(block cipher.Block) cipher.BlockMode {
+	return &ecb{block: block, isDecrypter: true}
+}
+
+// ******** Public functions ********
+
+// BlockSize returns the block size of the ECB mode.
+func (e *ecb) BlockSize() int {
+	return e.block.BlockSize()
+}
+
+// CryptBlocks encrypts or decrypts all blocks independently.
+func (e *ecb) CryptBlocks(dst []byte, src []byte) {
+	blockSize := e.block.BlockSize()
+	checkBlocks(dst, src, blockSize)
+
+	for len(src) > 0 {
+		if e.isDecrypter {
+			e.block.Decrypt(dst[:blockSize], src[:blockSize])
+		} else {
+			e.block.Encrypt(dst[:blockSize], src[:blockSize])
+		}
+
+		src = src[blockSize:]
+		dst = dst[blockSize:]
+	}
+}
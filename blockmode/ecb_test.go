@@ -0,0 +1,58 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockmode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// These are the ECB-AES128 values of NIST SP 800-38A, F.1.1 and F.1.2.
+const (
+	nistKey        = `2b7e151628aed2a6abf7158809cf4f3c`
+	nistPlaintext  = `6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51`
+	nistCiphertext = `3ad77bb40d7a3660a89ecaf32466ef97f5d3d58503b9699de785895a96fdbaaf`
+)
+
+// ******** Functional tests ********
+
+func TestECBNISTVector(t *testing.T) {
+	key, _ := hex.DecodeString(nistKey)
+	plaintext, _ := hex.DecodeString(nistPlaintext)
+	ciphertext, _ := hex.DecodeString(nistCiphertext)
+
+	block, _ := aes.NewCipher(key)
+
+	result := make([]byte, len(plaintext))
+	NewECBEncrypter(block).CryptBlocks(result, plaintext)
+	if !bytes.Equal(result, ciphertext) {
+		t.Fatalf(`Encryption result '%x' differs from vector`, result)
+	}
+
+	NewECBDecrypter(block).CryptBlocks(result, result)
+	if !bytes.Equal(result, plaintext) {
+		t.Fatalf(`Decryption result '%x' differs from vector`, result)
+	}
+}
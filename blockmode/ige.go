@@ -0,0 +1,110 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockmode
+
+import "crypto/cipher"
+
+// ******** Private types ********
+
+// ige implements the IGE (infinite garble extension) mode, where each block is chained
+// with the previous plaintext and ciphertext block:
+//
+//	C[i] = E(P[i] XOR C[i-1]) XOR P[i-1]
+//
+// The initialization vector consists of C[0] followed by P[0], as in OpenSSL and Telegram's MTProto.
+type ige struct {
+	block           cipher.Block
+	ciphertextChain []byte
+	plaintextChain  []byte
+	tmp             []byte
+	saved           []byte
+	isDecrypter     bool
+}
+
+// ******** Public creation functions ********
+
+// NewIGEEncrypter creates an IGE encrypter.
+// The length of the initialization vector must be twice the block size.
+func NewIGEEncrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return newIGE(block, iv, false)
+}
+
+// NewIGEDecrypter creates an IGE decrypter.
+// The length of the initialization vector must be twice the block size.
+func NewIGEDecrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return newIGE(block, iv, true)
+}
+
+// ******** Public functions ********
+
+// BlockSize returns the block size of the IGE mode.
+func (g *ige) BlockSize() int {
+	return g.block.BlockSize()
+}
+
+// CryptBlocks encrypts or decrypts blocks in IGE mode.
+// The chains are kept between calls.
+func (g *ige) CryptBlocks(dst []byte, src []byte) {
+	blockSize := g.block.BlockSize()
+	checkBlocks(dst, src, blockSize)
+
+	tmp := g.tmp
+	saved := g.saved
+	for len(src) > 0 {
+		// src and dst may be the same, so the input block has to be saved before the output block is written.
+		copy(saved, src[:blockSize])
+		out := dst[:blockSize]
+
+		if g.isDecrypter {
+			xorBlock(tmp, saved, g.plaintextChain)
+			g.block.Decrypt(tmp, tmp)
+			xorBlock(out, tmp, g.ciphertextChain)
+			copy(g.ciphertextChain, saved)
+			copy(g.plaintextChain, out)
+		} else {
+			xorBlock(tmp, saved, g.ciphertextChain)
+			g.block.Encrypt(tmp, tmp)
+			xorBlock(out, tmp, g.plaintextChain)
+			copy(g.plaintextChain, saved)
+			copy(g.ciphertextChain, out)
+		}
+
+		src = src[blockSize:]
+		dst = dst[blockSize:]
+	}
+}
+
+// ******** Private functions ********
+
+// newIGE creates an IGE mode.
+func newIGE(block cipher.Block, iv []byte, isDecrypter bool) *ige {
+	blockSize := block.BlockSize()
+	checkIV(iv, blockSize+blockSize)
+
+	return &ige{
+		block:           block,
+		ciphertextChain: append([]byte(nil), iv[:blockSize]...),
+		plaintextChain:  append([]byte(nil), iv[blockSize:]...),
+		tmp:             make([]byte, blockSize),
+		saved:           make([]byte, blockSize),
+		isDecrypter:     isDecrypter,
+	}
+}
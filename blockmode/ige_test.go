@@ -0,0 +1,79 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockmode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+// ******** Private variables ********
+
+// igeTestVectors are the AES-128 test vectors of the IGE specification by Ben Laurie,
+// which are also used in the tests of OpenSSL (igetest.c).
+var igeTestVectors = []struct {
+	key        string
+	iv         string
+	plaintext  string
+	ciphertext string
+}{
+	{
+		`000102030405060708090a0b0c0d0e0f`,
+		`000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f`,
+		`0000000000000000000000000000000000000000000000000000000000000000`,
+		`1a8519a6557be652e9da8e43da4ef4453cf456b4ca488aa383c79c98b34797cb`,
+	},
+	{
+		`5468697320697320616e20696d706c65`,
+		`6d656e746174696f6e206f6620494745206d6f646520666f72204f70656e5353`,
+		`99706487a1cde613bc6de0b6f24b1c7aa448c8b9c3403e3467a8cad89340f53b`,
+		`4c2e204c6574277320686f70652042656e20676f74206974207269676874210a`,
+	},
+}
+
+// ******** Functional tests ********
+
+func TestIGEVectors(t *testing.T) {
+	for i, tv := range igeTestVectors {
+		key, _ := hex.DecodeString(tv.key)
+		iv, _ := hex.DecodeString(tv.iv)
+		plaintext, _ := hex.DecodeString(tv.plaintext)
+		ciphertext, _ := hex.DecodeString(tv.ciphertext)
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatalf(`Could not create AES cipher: %v`, err)
+		}
+
+		result := make([]byte, len(plaintext))
+		NewIGEEncrypter(block, iv).CryptBlocks(result, plaintext)
+		if !bytes.Equal(result, ciphertext) {
+			t.Fatalf(`Encryption result '%x' of vector %d differs from vector`, result, i)
+		}
+
+		NewIGEDecrypter(block, iv).CryptBlocks(result, result)
+		if !bytes.Equal(result, plaintext) {
+			t.Fatalf(`Decryption result '%x' of vector %d differs from vector`, result, i)
+		}
+	}
+}
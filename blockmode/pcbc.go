@@ -0,0 +1,105 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockmode
+
+import "crypto/cipher"
+
+// ******** Private types ********
+
+// pcbc implements the PCBC mode, where each block is chained with the previous plaintext and ciphertext block:
+//
+//	C[i] = E(P[i] XOR P[i-1] XOR C[i-1]), with P[0] XOR C[0] = IV
+//
+// The chain holds P[i-1] XOR C[i-1].
+type pcbc struct {
+	block       cipher.Block
+	chain       []byte
+	tmp         []byte
+	saved       []byte
+	isDecrypter bool
+}
+
+// ******** Public creation functions ********
+
+// NewPCBCEncrypter creates a PCBC encrypter.
+// The length of the initialization vector must be the block size.
+func NewPCBCEncrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return newPCBC(block, iv, false)
+}
+
+// NewPCBCDecrypter creates a PCBC decrypter.
+// The length of the initialization vector must be the block size.
+func NewPCBCDecrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return newPCBC(block, iv, true)
+}
+
+// ******** Public functions ********
+
+// BlockSize returns the block size of the PCBC mode.
+func (p *pcbc) BlockSize() int {
+	return p.block.BlockSize()
+}
+
+// CryptBlocks encrypts or decrypts blocks in PCBC mode.
+// The chain is kept between calls.
+func (p *pcbc) CryptBlocks(dst []byte, src []byte) {
+	blockSize := p.block.BlockSize()
+	checkBlocks(dst, src, blockSize)
+
+	chain := p.chain
+	tmp := p.tmp
+	saved := p.saved
+	for len(src) > 0 {
+		// src and dst may be the same, so the input block has to be saved before the output block is written.
+		copy(saved, src[:blockSize])
+		out := dst[:blockSize]
+
+		if p.isDecrypter {
+			p.block.Decrypt(tmp, saved)
+			xorBlock(out, tmp, chain)
+		} else {
+			xorBlock(tmp, saved, chain)
+			p.block.Encrypt(out, tmp)
+		}
+
+		// The chain is always P[i] XOR C[i].
+		xorBlock(chain, saved, out)
+
+		src = src[blockSize:]
+		dst = dst[blockSize:]
+	}
+}
+
+// ******** Private functions ********
+
+// newPCBC creates a PCBC mode.
+func newPCBC(block cipher.Block, iv []byte, isDecrypter bool) *pcbc {
+	blockSize := block.BlockSize()
+	checkIV(iv, blockSize)
+
+	return &pcbc{
+		block:       block,
+		chain:       append([]byte(nil), iv...),
+		tmp:         make([]byte, blockSize),
+		saved:       make([]byte, blockSize),
+		isDecrypter: isDecrypter,
+	}
+}
@@ -0,0 +1,83 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockmode
+
+import (
+	"bytes"
+	"crypto/des"
+	"encoding/hex"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// These are the PCBC values of the DES tests of OpenSSL (destest.c, cbc_key, cbc_iv, cbc_data and pcbc_ok).
+// The data is the string with its terminating zero byte, padded with zero bytes to full blocks.
+const (
+	pcbcKey        = `0123456789abcdef`
+	pcbcIV         = `fedcba9876543210`
+	pcbcPlaintext  = `37363534333231204e6f77206973207468652074696d6520666f722000000000`
+	pcbcCiphertext = `ccd173ffab2039f46decb470a0e56b15aea6bf61ed7d9c9ff717463b8ab3cc88`
+)
+
+// ******** Functional tests ********
+
+func TestPCBCOpenSSLVector(t *testing.T) {
+	key, _ := hex.DecodeString(pcbcKey)
+	iv, _ := hex.DecodeString(pcbcIV)
+	plaintext, _ := hex.DecodeString(pcbcPlaintext)
+	ciphertext, _ := hex.DecodeString(pcbcCiphertext)
+
+	block, err := des.NewCipher(key)
+	if err != nil {
+		t.Fatalf(`Could not create DES cipher: %v`, err)
+	}
+
+	result := make([]byte, len(plaintext))
+	NewPCBCEncrypter(block, iv).CryptBlocks(result, plaintext)
+	if !bytes.Equal(result, ciphertext) {
+		t.Fatalf(`Encryption result '%x' differs from vector`, result)
+	}
+
+	NewPCBCDecrypter(block, iv).CryptBlocks(result, result)
+	if !bytes.Equal(result, plaintext) {
+		t.Fatalf(`Decryption result '%x' differs from vector`, result)
+	}
+}
+
+func TestPCBCPropagation(t *testing.T) {
+	key, _ := hex.DecodeString(pcbcKey)
+	iv, _ := hex.DecodeString(pcbcIV)
+	ciphertext, _ := hex.DecodeString(pcbcCiphertext)
+	block, _ := des.NewCipher(key)
+
+	// A modification of one ciphertext block garbles all following plaintext blocks.
+	ciphertext[0] ^= 1
+	result := make([]byte, len(ciphertext))
+	NewPCBCDecrypter(block, iv).CryptBlocks(result, ciphertext)
+
+	plaintext, _ := hex.DecodeString(pcbcPlaintext)
+	for i := 0; i < len(result); i += des.BlockSize {
+		if bytes.Equal(result[i:i+des.BlockSize], plaintext[i:i+des.BlockSize]) {
+			t.Fatalf(`Block %d is not garbled`, i/des.BlockSize)
+		}
+	}
+}
@@ -23,6 +23,8 @@
 // It can be used for block ciphers ([crypto/cipher/Block] or [crypto/cipher/BlockMode])
 // in e.g. ECB, CBC or PCBC mode, as they require that the size of the data to be encrypted
 // is a multiple of the block size.
+// The standard library only implements the CBC mode.
+// The package [github.com/xformerfhs/blockpad/blockmode] implements the ECB, PCBC and IGE modes.
 package blockpad
 
 import "errors"
@@ -86,6 +88,22 @@ const (
 	// This padding is *not* susceptible to a padding oracle attack.
 	NotLastByte
 
+	// McryptZero implements the zero padding of the legacy PHP mcrypt extension.
+	// Zero bytes are only appended if the data is not a multiple of the block size,
+	// so padding never adds a full block. Unpad removes up to block size - 1 trailing zero bytes.
+	// This padding is ambiguous: trailing zero bytes of the data can not be distinguished from padding
+	// and are removed by Unpad. It should only be used to read legacy data.
+	// Unpad never returns an error, so this padding is *not* susceptible to a padding oracle attack.
+	McryptZero
+
+	// ISO97971M3 implements ISO/IEC 9797-1 padding method 3.
+	// A prefix block with the length of the data in bits is prepended and
+	// zero bytes are only appended if the data is not a multiple of the block size.
+	// Unpad checks the length in the prefix block against the length of the padded data.
+	// This padding can not be used with block sizes below 8 for data whose length in bits does not fit into a block.
+	// This padding should only be used with integrity protection as it is susceptible to a padding oracle attack.
+	ISO97971M3
+
 	// maxAlgorithm is a helper constant and always contains the maximum defined padding type constant.
 	// It must always be the last constant in this const block!
 	maxAlgorithm = iota - 2
@@ -107,4 +125,20 @@ var (
 	// It is deliberately not stated what exactly is wrong so that
 	// an attacker does not obtain too much information.
 	ErrInvalidPadding = errors.New(`invalid padding`)
+
+	// ErrInvalidBlockMode means that the block size of a block mode does not match the block size of the padder.
+	ErrInvalidBlockMode = errors.New(`block mode block size does not match padder block size`)
+
+	// ErrWriterClosed means that a write was attempted on a closed Writer.
+	ErrWriterClosed = errors.New(`write to closed writer`)
+
+	// ErrInvalidIV means that the initialization vector does not have a valid length.
+	// It is also returned by the subpackages that need an initialization vector.
+	ErrInvalidIV = errors.New(`invalid initialization vector`)
+
+	// ErrUnknownDataLen means that a Writer for a padding with a prefix block has been created without the data length.
+	ErrUnknownDataLen = errors.New(`padding with prefix block needs the data length in advance`)
+
+	// ErrDataLenMismatch means that the length of the data written to a Writer differs from the announced length.
+	ErrDataLenMismatch = errors.New(`data length differs from announced length`)
 )
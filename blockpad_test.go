@@ -23,6 +23,8 @@ package blockpad
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"github.com/xformerfhs/blockpad/internal/slicehelper"
 	mrand "math/rand"
@@ -179,7 +181,10 @@ func TestPadCrossUnpad(t *testing.T) {
 				!(padAlgorithm == ArbitraryTailByte || otherPadAlgorithm == ArbitraryTailByte) &&
 				!(padAlgorithm == NotLastByte || otherPadAlgorithm == NotLastByte) &&
 				!((padAlgorithm == PKCS7 || padAlgorithm == X923 || padAlgorithm == RFC4303) && otherPadAlgorithm == ISO10126) &&
-				!(padAlgorithm == ISO78164 && otherPadAlgorithm == Zero) {
+				!(padAlgorithm == ISO78164 && otherPadAlgorithm == Zero) &&
+				!(padAlgorithm == McryptZero && otherPadAlgorithm == Zero) &&
+				!(padAlgorithm == ISO97971M3 && (otherPadAlgorithm == Zero || otherPadAlgorithm == ISO78164)) &&
+				otherPadAlgorithm != McryptZero {
 				var otherPadder *BlockPad
 				otherPadder, err = NewBlockPadding(otherPadAlgorithm, testBlockSize)
 				if err != nil {
@@ -219,6 +224,145 @@ func TestInvalidZeroPadding(t *testing.T) {
 	_ = padder.Pad(data)
 }
 
+func TestMcryptZeroPadding(t *testing.T) {
+	padder, err := NewBlockPadding(McryptZero, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, McryptZero, err)
+	}
+
+	data := make([]byte, testBlockSize<<1)
+	slicehelper.Fill(data, 77)
+
+	paddedData := padder.Pad(data)
+	if !bytes.Equal(paddedData, data) {
+		t.Fatalf(`%s: data that is a multiple of the block size has been padded`, padder.String())
+	}
+
+	paddedData = padder.Pad(data[:testBlockSize+3])
+	if len(paddedData) != testBlockSize<<1 || !bytes.Equal(paddedData[testBlockSize+3:], make([]byte, testBlockSize-3)) {
+		t.Fatalf(`%s: wrong padding: %02x`, padder.String(), paddedData)
+	}
+
+	// Trailing zero bytes of the data are indistinguishable from padding.
+	data[testBlockSize+2] = 0
+
+	var unpaddedData []byte
+	unpaddedData, err = padder.Unpad(padder.Pad(data[:testBlockSize+3]))
+	if err != nil {
+		t.Fatalf(`%s: unpad failed: %v`, padder.String(), err)
+	}
+	if !bytes.Equal(unpaddedData, data[:testBlockSize+2]) {
+		t.Fatalf(`%s: trailing zero byte has not been removed: %02x`, padder.String(), unpaddedData)
+	}
+
+	// A full block of zero bytes is never padding.
+	unpaddedData, err = padder.Unpad(make([]byte, testBlockSize))
+	if err != nil {
+		t.Fatalf(`%s: unpad of zero block failed: %v`, padder.String(), err)
+	}
+	if len(unpaddedData) != 1 {
+		t.Fatalf(`%s: wrong length %d of unpadded zero block`, padder.String(), len(unpaddedData))
+	}
+}
+
+func TestISO97971M3Padding(t *testing.T) {
+	padder, err := NewBlockPadding(ISO97971M3, 8)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, ISO97971M3, err)
+	}
+
+	// The prefix block contains the length in bits, the data is padded with as few zero bytes as possible.
+	for _, tv := range []struct {
+		data   string
+		padded string
+	}{
+		{``, `0000000000000000`},
+		{`abc`, `00000000000000186162630000000000`},
+		{`Now is the time for all `, `00000000000000c04e6f77206973207468652074696d6520666f7220616c6c20`},
+	} {
+		paddedData := padder.Pad([]byte(tv.data))
+		if hex.EncodeToString(paddedData) != tv.padded {
+			t.Fatalf(`%s: wrong padding of '%s': %02x`, padder.String(), tv.data, paddedData)
+		}
+
+		var unpaddedData []byte
+		unpaddedData, err = padder.Unpad(paddedData)
+		if err != nil {
+			t.Fatalf(`%s: unpad of '%s' failed: %v`, padder.String(), tv.data, err)
+		}
+		if string(unpaddedData) != tv.data {
+			t.Fatalf(`%s: unpadded data '%s' is not '%s'`, padder.String(), unpaddedData, tv.data)
+		}
+	}
+
+	// Trailing zero bytes of the data are preserved, as the length is known.
+	data := []byte{1, 2, 3, 0, 0}
+	unpaddedData, err := padder.Unpad(padder.Pad(data))
+	if err != nil {
+		t.Fatalf(`%s: unpad failed: %v`, padder.String(), err)
+	}
+	if !bytes.Equal(unpaddedData, data) {
+		t.Fatalf(`%s: trailing zero bytes have not been preserved: %02x`, padder.String(), unpaddedData)
+	}
+}
+
+func TestInvalidISO97971M3Padding(t *testing.T) {
+	padder, err := NewBlockPadding(ISO97971M3, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, ISO97971M3, err)
+	}
+
+	_, data := makeFixedLenTestSlice(testBlockSize + 3)
+	paddedData := padder.Pad(data)
+	lastIndex := len(paddedData) - 1
+
+	// A length that is one byte too large is not detected, if the additional byte is a zero padding byte.
+	for _, modify := range []func([]byte){
+		func(b []byte) { binary.BigEndian.PutUint16(b[testBlockSize-2:], (3*testBlockSize+1)<<3) }, // Length too large
+		func(b []byte) { binary.BigEndian.PutUint16(b[testBlockSize-2:], testBlockSize<<3) },       // Length too small
+		func(b []byte) { b[testBlockSize-1] |= 1 },                                                 // Length not a multiple of 8 bits
+		func(b []byte) { b[0] = 1 },                                                                // Length does not fit into 64 bits
+		func(b []byte) { b[lastIndex] = 1 },                                                        // Padding byte not zero
+		func(b []byte) { copy(b, make([]byte, testBlockSize)) },                                    // Length 0 with data
+	} {
+		invalidData := bytes.Clone(paddedData)
+		modify(invalidData)
+
+		_, err = padder.Unpad(invalidData)
+		if !errors.Is(err, ErrInvalidPadding) {
+			t.Fatalf(`%s: wrong error unpadding invalid padded data %02x: %v`, padder.String(), invalidData, err)
+		}
+	}
+
+	// An additional block of data does not match the length.
+	_, err = padder.Unpad(append(bytes.Clone(paddedData), make([]byte, testBlockSize)...))
+	if !errors.Is(err, ErrInvalidPadding) {
+		t.Fatalf(`%s: wrong error unpadding data with additional block: %v`, padder.String(), err)
+	}
+
+	// There has to be a prefix block.
+	_, err = padder.Unpad([]byte{})
+	if !errors.Is(err, ErrInvalidPaddedDataLen) {
+		t.Fatalf(`%s: wrong error unpadding empty data: %v`, padder.String(), err)
+	}
+}
+
+func TestISO97971M3LengthTooLarge(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`no panic when the length does not fit into the prefix block`)
+		}
+	}()
+
+	padder, err := NewBlockPadding(ISO97971M3, 1)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, ISO97971M3, err)
+	}
+
+	// 32 bytes are 256 bits, which do not fit into one byte.
+	_ = padder.Pad(make([]byte, 32))
+}
+
 func TestInvalidPKCS7Padding(t *testing.T) {
 	padder, err := NewBlockPadding(PKCS7, testBlockSize)
 	if err != nil {
@@ -323,7 +467,7 @@ func TestUnpadNoPadding(t *testing.T) {
 	data[len(data)-1] = 0x5a
 
 	for padType := Zero; padType <= maxAlgorithm; padType++ {
-		if padType != ArbitraryTailByte && padType != NotLastByte {
+		if padType != ArbitraryTailByte && padType != NotLastByte && padType != McryptZero {
 			padder, err := NewBlockPadding(padType, testBlockSize)
 			if err != nil {
 				t.Fatalf(`Error creating BlockPad with pad type %d: %v`, padType, err)
@@ -434,7 +578,7 @@ func makeTestSlice(len int) []byte {
 // makeZeroSafeTestSlice takes a test slice and makes it Zero-safe, if necessary.
 func makeZeroSafeTestSlice(padType PadAlgorithm, dataLen int, data []byte) (int, []byte) {
 	// Zero padding will not work if last byte is 0.
-	if padType == Zero && data[dataLen-1] == 0 {
+	if (padType == Zero || padType == McryptZero) && data[dataLen-1] == 0 {
 		data[dataLen-1] = 0xff
 	}
 
@@ -475,6 +619,13 @@ func doPadAndUnpadLastBlock(t *testing.T, padder *BlockPad, data []byte, dataLen
 	fullData, paddedLastBlock := padder.PadLastBlock(data)
 	lastData := data[len(fullData):]
 
+	// A padding with a prefix block can only be removed from the complete padded data.
+	prefixBlock := padder.PrefixBlock(dataLen)
+	if len(prefixBlock) != 0 {
+		paddedLastBlock = slicehelper.Concat(prefixBlock, fullData, paddedLastBlock)
+		lastData = data
+	}
+
 	unpaddedLastBlock, err := padder.Unpad(paddedLastBlock)
 	if err != nil {
 		t.Fatalf(`%s: UnpadLastBlock failed (dataLen=%d): %v`, padder.String(), dataLen, err)
@@ -0,0 +1,379 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cms implements the content encryption of the Cryptographic Message Syntax (CMS) as specified in RFC 5652.
+//
+// It builds and parses EncryptedData structures and decrypts the content of EnvelopedData structures
+// when the content-encryption key is known. The recipient information of EnvelopedData structures
+// is not processed.
+// The content is encrypted with AES-128-CBC, AES-192-CBC, AES-256-CBC or DES-EDE3-CBC and PKCS#7 padding.
+// All structures are DER encoded.
+//
+// ATTENTION: CMS content encryption has no integrity protection.
+// An invalid padding is reported as ErrDecryptionFailed, which may act as a padding oracle.
+package cms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/asn1"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/padmode"
+	"io"
+)
+
+// ******** Public types ********
+
+// ContentEncryption is the type that holds content encryption algorithms.
+type ContentEncryption byte
+
+// ******** Public constants ********
+
+// These are the valid content encryption algorithms.
+const (
+	// minContentEncryption is a helper constant and always contains the minimum defined content encryption constant.
+	// It must always be the first constant in this const block!
+	minContentEncryption ContentEncryption = 0
+
+	// AES128CBC is AES-128 in CBC mode.
+	AES128CBC ContentEncryption = iota - 1
+
+	// AES192CBC is AES-192 in CBC mode.
+	AES192CBC
+
+	// AES256CBC is AES-256 in CBC mode.
+	AES256CBC
+
+	// DESEDE3CBC is Triple-DES in CBC mode. It should only be used for compatibility with legacy systems.
+	DESEDE3CBC
+
+	// maxContentEncryption is a helper constant and always contains the maximum defined content encryption constant.
+	// It must always be the last constant in this const block!
+	maxContentEncryption = iota - 2
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrDecryptionFailed means that the content could not be decrypted.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrDecryptionFailed = errors.New(`content decryption failed`)
+
+	// ErrInvalidFormat means that the data is not a valid CMS structure of the expected type.
+	ErrInvalidFormat = errors.New(`invalid CMS format`)
+
+	// ErrUnsupportedAlgorithm means that the content encryption algorithm of a CMS structure is not supported.
+	ErrUnsupportedAlgorithm = errors.New(`unsupported content encryption algorithm`)
+
+	// ErrInvalidContentEncryption means that the supplied content encryption algorithm is invalid.
+	ErrInvalidContentEncryption = errors.New(`invalid content encryption algorithm`)
+
+	// ErrInvalidKeySize means that the key does not have the size required by the content encryption algorithm.
+	ErrInvalidKeySize = errors.New(`invalid key size`)
+)
+
+// ******** Private types ********
+
+// algorithmIdentifier is the ASN.1 structure AlgorithmIdentifier.
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+// contentInfo is the ASN.1 structure ContentInfo.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+// encryptedContentInfo is the ASN.1 structure EncryptedContentInfo.
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm algorithmIdentifier
+	EncryptedContent           []byte `asn1:"optional,tag:0"`
+}
+
+// encryptedData is the ASN.1 structure EncryptedData.
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+	UnprotectedAttrs     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// envelopedData is the ASN.1 structure EnvelopedData.
+type envelopedData struct {
+	Version              int
+	OriginatorInfo       asn1.RawValue `asn1:"optional,tag:0"`
+	RecipientInfos       asn1.RawValue
+	EncryptedContentInfo encryptedContentInfo
+	UnprotectedAttrs     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// contentEncryptionInfo holds the parameters of a content encryption algorithm.
+type contentEncryptionInfo struct {
+	oid       asn1.ObjectIdentifier
+	keySize   int
+	blockSize int
+	newCipher func([]byte) (cipher.Block, error)
+}
+
+// ******** Private constants ********
+
+// These are the object identifiers of the content types.
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEnvelopedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+	oidEncryptedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+)
+
+// contentEncryptionImplementation holds the parameters of the content encryption algorithms.
+var contentEncryptionImplementation = []contentEncryptionInfo{
+	{oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}, keySize: 16, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}, keySize: 24, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}, keySize: 32, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}, keySize: 24, blockSize: des.BlockSize, newCipher: des.NewTripleDESCipher},
+}
+
+// ******** Public functions ********
+
+// EncryptData encrypts content with the key and returns a DER encoded ContentInfo holding an EncryptedData structure.
+// The initialization vector is read from rand.
+func EncryptData(rand io.Reader, contentEncryption ContentEncryption, key []byte, content []byte) ([]byte, error) {
+	eci, err := encryptContent(rand, contentEncryption, key, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var ed []byte
+	ed, err = asn1.Marshal(encryptedData{Version: 0, EncryptedContentInfo: *eci})
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalContentInfo(oidEncryptedData, ed)
+}
+
+// DecryptData decrypts a DER encoded ContentInfo holding an EncryptedData structure with the key.
+// It returns the decrypted content.
+func DecryptData(data []byte, key []byte) ([]byte, error) {
+	content, err := parseContentInfo(data, oidEncryptedData)
+	if err != nil {
+		return nil, err
+	}
+
+	var ed encryptedData
+	err = unmarshalAll(content, &ed)
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptContent(&ed.EncryptedContentInfo, key)
+}
+
+// EncryptContent encrypts content with the content-encryption key and returns a DER encoded
+// EncryptedContentInfo structure. It can be used to build an EnvelopedData structure.
+// The initialization vector is read from rand.
+func EncryptContent(rand io.Reader, contentEncryption ContentEncryption, contentEncryptionKey []byte, content []byte) ([]byte, error) {
+	eci, err := encryptContent(rand, contentEncryption, contentEncryptionKey, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(*eci)
+}
+
+// DecryptEnvelopedData decrypts the content of a DER encoded ContentInfo holding an EnvelopedData structure
+// with the content-encryption key. The recipient information is not processed.
+// It returns the decrypted content.
+func DecryptEnvelopedData(data []byte, contentEncryptionKey []byte) ([]byte, error) {
+	content, err := parseContentInfo(data, oidEnvelopedData)
+	if err != nil {
+		return nil, err
+	}
+
+	var ed envelopedData
+	err = unmarshalAll(content, &ed)
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptContent(&ed.EncryptedContentInfo, contentEncryptionKey)
+}
+
+// ******** Private functions ********
+
+// encryptContent pads and encrypts content and returns the EncryptedContentInfo structure.
+func encryptContent(rand io.Reader, contentEncryption ContentEncryption, key []byte, content []byte) (*encryptedContentInfo, error) {
+	if contentEncryption > maxContentEncryption {
+		return nil, ErrInvalidContentEncryption
+	}
+
+	cei := &contentEncryptionImplementation[contentEncryption]
+
+	// 1. Create a random initialization vector.
+	iv := make([]byte, cei.blockSize)
+	_, err := io.ReadFull(rand, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	var ivParam []byte
+	ivParam, err = asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Pad and encrypt.
+	var block cipher.Block
+	var padder *blockpad.BlockPad
+	block, padder, err = cei.newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypter, _ := padmode.NewPaddedEncrypter(cipher.NewCBCEncrypter(block, iv), padder)
+
+	return &encryptedContentInfo{
+		ContentType: oidData,
+		ContentEncryptionAlgorithm: algorithmIdentifier{
+			Algorithm:  cei.oid,
+			Parameters: asn1.RawValue{FullBytes: ivParam},
+		},
+		EncryptedContent: encrypter.Seal(nil, content),
+	}, nil
+}
+
+// decryptContent decrypts and unpads the content of an EncryptedContentInfo structure.
+func decryptContent(eci *encryptedContentInfo, key []byte) ([]byte, error) {
+	cei := contentEncryptionByOID(eci.ContentEncryptionAlgorithm.Algorithm)
+	if cei == nil {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	var iv []byte
+	err := unmarshalAll(eci.ContentEncryptionAlgorithm.Parameters.FullBytes, &iv)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != cei.blockSize {
+		return nil, ErrInvalidFormat
+	}
+
+	encryptedContentLen := len(eci.EncryptedContent)
+	if encryptedContentLen == 0 || encryptedContentLen%cei.blockSize != 0 {
+		return nil, ErrInvalidFormat
+	}
+
+	var block cipher.Block
+	var padder *blockpad.BlockPad
+	block, padder, err = cei.newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypter, _ := padmode.NewPaddedDecrypter(cipher.NewCBCDecrypter(block, iv), padder)
+
+	var result []byte
+	result, err = decrypter.Open(nil, eci.EncryptedContent)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return result, nil
+}
+
+// parseContentInfo parses a ContentInfo structure and checks its content type.
+// It returns the DER encoded content.
+func parseContentInfo(data []byte, contentType asn1.ObjectIdentifier) ([]byte, error) {
+	var ci contentInfo
+	err := unmarshalAll(data, &ci)
+	if err != nil {
+		return nil, err
+	}
+
+	// The content is explicitly tagged with [0].
+	if !ci.ContentType.Equal(contentType) ||
+		ci.Content.Class != asn1.ClassContextSpecific ||
+		ci.Content.Tag != 0 ||
+		!ci.Content.IsCompound {
+		return nil, ErrInvalidFormat
+	}
+
+	return ci.Content.Bytes, nil
+}
+
+// marshalContentInfo builds a DER encoded ContentInfo structure with the DER encoded content.
+func marshalContentInfo(contentType asn1.ObjectIdentifier, content []byte) ([]byte, error) {
+	return asn1.Marshal(contentInfo{
+		ContentType: contentType,
+		Content: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      content,
+		},
+	})
+}
+
+// unmarshalAll parses DER data into a value and checks that there is no trailing data.
+func unmarshalAll(data []byte, value any) error {
+	rest, err := asn1.Unmarshal(data, value)
+	if err != nil || len(rest) != 0 {
+		return ErrInvalidFormat
+	}
+
+	return nil
+}
+
+// contentEncryptionByOID returns the content encryption algorithm for an object identifier.
+// It returns nil if there is no content encryption algorithm with this object identifier.
+func contentEncryptionByOID(oid asn1.ObjectIdentifier) *contentEncryptionInfo {
+	for i := range contentEncryptionImplementation {
+		if contentEncryptionImplementation[i].oid.Equal(oid) {
+			return &contentEncryptionImplementation[i]
+		}
+	}
+
+	return nil
+}
+
+// newBlock checks the key size and creates the block cipher and the padder.
+func (cei *contentEncryptionInfo) newBlock(key []byte) (cipher.Block, *blockpad.BlockPad, error) {
+	if len(key) != cei.keySize {
+		return nil, nil, ErrInvalidKeySize
+	}
+
+	block, err := cei.newCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var padder *blockpad.BlockPad
+	padder, err = blockpad.NewBlockPadding(blockpad.PKCS7, cei.blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, padder, nil
+}
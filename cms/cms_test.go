@@ -0,0 +1,254 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cms
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// ******** Private types ********
+
+// fixture holds an EncryptedData structure that has been created by OpenSSL.
+type fixture struct {
+	name      string
+	key       string
+	encrypted string
+}
+
+// ******** Private constants ********
+
+// fixtureContent is the content of the fixtures.
+const fixtureContent = "Archived S/MIME content.\n"
+
+// These are the keys of the fixtures.
+const (
+	fixtureKey16 = `000102030405060708090a0b0c0d0e0f`
+	fixtureKey24 = fixtureKey16 + `1011121314151617`
+	fixtureKey32 = fixtureKey16 + `101112131415161718191a1b1c1d1e1f`
+)
+
+// envelopedFixture is an EnvelopedData structure created with
+// "openssl cms -encrypt -binary -aes-128-cbc -secretkey <fixtureKey16> -secretkeyid 0102 -outform DER".
+const envelopedFixture = `MIGWBgkqhkiG9w0BBwOggYgwgYUCAQIxMqIwAgEEMAQEAgECMAsGCWCGSAFlAwQBBQQYZlg7KJe5grs3dGkv7qfMIuxPZ+FW/yO3MEwGCSqGSIb3DQEHATAdBglghkgBZQMEAQIEEHHa7sjUgm6RsvH2F9IOEAiAINcWW8NRfQ/O4fxaG+edARZ7DXpQtzDYQTYi4HAKxWu+`
+
+// envelopedFixtureCEK is the content-encryption key of the enveloped fixture.
+// It has been unwrapped from the KEK recipient info with the key encryption key fixtureKey16.
+const envelopedFixtureCEK = `0e9af332f68da1936f965d7cfafbf427`
+
+// ******** Private variables ********
+
+// fixtures contains EncryptedData structures created with
+// "openssl cms -EncryptedData_encrypt -binary -<cipher> -secretkey <key> -outform DER".
+var fixtures = []fixture{
+	{
+		name:      `aes-128-cbc`,
+		key:       fixtureKey16,
+		encrypted: `MGAGCSqGSIb3DQEHBqBTMFECAQAwTAYJKoZIhvcNAQcBMB0GCWCGSAFlAwQBAgQQ+W9emB8AyjXwgeFNwjSQRoAguvtyfaX3Zhd96vAcYVUiR3YF4EyFmobn2RhB4WJqCqg=`,
+	},
+	{
+		name:      `aes-192-cbc`,
+		key:       fixtureKey24,
+		encrypted: `MGAGCSqGSIb3DQEHBqBTMFECAQAwTAYJKoZIhvcNAQcBMB0GCWCGSAFlAwQBFgQQk0WkbBUdj+OxUM3Bd/3pgoAgGTOyILoKcw1x5nGc9ts+qpNs3uuOSlSVXOeZ/J0eIaE=`,
+	},
+	{
+		name:      `aes-256-cbc`,
+		key:       fixtureKey32,
+		encrypted: `MGAGCSqGSIb3DQEHBqBTMFECAQAwTAYJKoZIhvcNAQcBMB0GCWCGSAFlAwQBKgQQ2wVtJ2j+S3xW2Bdcr4AZSYAgmyWQtPI3kP180G/pgrfJrtaskqEVUrCkutuYKuBOO2U=`,
+	},
+	{
+		name:      `des-ede3-cbc`,
+		key:       fixtureKey24,
+		encrypted: `MFcGCSqGSIb3DQEHBqBKMEgCAQAwQwYJKoZIhvcNAQcBMBQGCCqGSIb3DQMHBAg/AkML4xdwlYAgk8pWSkmGr0vxYwoMIfs05FQ+gJtfgDYkpzB5e821KFc=`,
+	},
+}
+
+// ******** Fixture tests ********
+
+func TestDecryptDataFixtures(t *testing.T) {
+	for _, f := range fixtures {
+		content, err := DecryptData(decodeBase64(t, f.encrypted), decodeHex(t, f.key))
+		if err != nil {
+			t.Fatalf(`%s: decryption failed: %v`, f.name, err)
+		}
+		if string(content) != fixtureContent {
+			t.Fatalf(`%s: decrypted content '%s' differs from fixture content`, f.name, content)
+		}
+	}
+}
+
+func TestDecryptEnvelopedDataFixture(t *testing.T) {
+	content, err := DecryptEnvelopedData(decodeBase64(t, envelopedFixture), decodeHex(t, envelopedFixtureCEK))
+	if err != nil {
+		t.Fatalf(`Decryption failed: %v`, err)
+	}
+	if string(content) != fixtureContent {
+		t.Fatalf(`Decrypted content '%s' differs from fixture content`, content)
+	}
+}
+
+// ******** Functional tests ********
+
+func TestEncryptDecryptData(t *testing.T) {
+	for contentEncryption := minContentEncryption; contentEncryption <= maxContentEncryption; contentEncryption++ {
+		key := makeKey(t, contentEncryption)
+
+		for contentLen := 0; contentLen <= 40; contentLen++ {
+			content := bytes.Repeat([]byte{'c'}, contentLen)
+
+			encrypted, err := EncryptData(rand.Reader, contentEncryption, key, content)
+			if err != nil {
+				t.Fatalf(`Encryption with algorithm %d failed: %v`, contentEncryption, err)
+			}
+
+			var decrypted []byte
+			decrypted, err = DecryptData(encrypted, key)
+			if err != nil {
+				t.Fatalf(`Decryption with algorithm %d failed: %v`, contentEncryption, err)
+			}
+			if !bytes.Equal(decrypted, content) {
+				t.Fatalf(`Decrypted content with algorithm %d differs from content`, contentEncryption)
+			}
+		}
+	}
+}
+
+func TestEncryptContentEnveloped(t *testing.T) {
+	cek := makeKey(t, AES256CBC)
+	content := []byte(fixtureContent)
+
+	eci, err := EncryptContent(rand.Reader, AES256CBC, cek, content)
+	if err != nil {
+		t.Fatalf(`Encryption failed: %v`, err)
+	}
+
+	// Build an EnvelopedData structure with an empty set of recipient infos around the encrypted content.
+	var ed []byte
+	ed, err = asn1.Marshal(struct {
+		Version              int
+		RecipientInfos       asn1.RawValue
+		EncryptedContentInfo asn1.RawValue
+	}{
+		Version:              2,
+		RecipientInfos:       asn1.RawValue{FullBytes: []byte{0x31, 0x00}},
+		EncryptedContentInfo: asn1.RawValue{FullBytes: eci},
+	})
+	if err != nil {
+		t.Fatalf(`Could not marshal EnvelopedData: %v`, err)
+	}
+
+	var data []byte
+	data, err = marshalContentInfo(oidEnvelopedData, ed)
+	if err != nil {
+		t.Fatalf(`Could not marshal ContentInfo: %v`, err)
+	}
+
+	var decrypted []byte
+	decrypted, err = DecryptEnvelopedData(data, cek)
+	if err != nil {
+		t.Fatalf(`Decryption failed: %v`, err)
+	}
+	if !bytes.Equal(decrypted, content) {
+		t.Fatal(`Decrypted content differs from content`)
+	}
+}
+
+// ******** Test invalid data ********
+
+func TestInvalidData(t *testing.T) {
+	encrypted := decodeBase64(t, fixtures[0].encrypted)
+	key := decodeHex(t, fixtures[0].key)
+
+	_, err := DecryptData(encrypted[:len(encrypted)-1], key)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf(`Wrong error decrypting truncated data: %v`, err)
+	}
+
+	_, err = DecryptEnvelopedData(encrypted, key)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf(`Wrong error decrypting EncryptedData as EnvelopedData: %v`, err)
+	}
+
+	_, err = DecryptData(encrypted, key[1:])
+	if !errors.Is(err, ErrInvalidKeySize) {
+		t.Fatalf(`Wrong error decrypting with wrong key size: %v`, err)
+	}
+
+	// Change the last byte of the first block, which changes the last padding byte of the second block.
+	manipulated := bytes.Clone(encrypted)
+	manipulated[len(manipulated)-17] ^= 0xf0
+	_, err = DecryptData(manipulated, key)
+	if !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf(`Wrong error decrypting data with invalid padding: %v`, err)
+	}
+}
+
+// ******** Test invalid parameters ********
+
+func TestInvalidParameters(t *testing.T) {
+	_, err := EncryptData(rand.Reader, maxContentEncryption+1, make([]byte, 16), []byte(fixtureContent))
+	if !errors.Is(err, ErrInvalidContentEncryption) {
+		t.Fatalf(`Wrong error encrypting with invalid content encryption: %v`, err)
+	}
+
+	_, err = EncryptData(rand.Reader, AES192CBC, make([]byte, 16), []byte(fixtureContent))
+	if !errors.Is(err, ErrInvalidKeySize) {
+		t.Fatalf(`Wrong error encrypting with wrong key size: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// makeKey creates a random key for a content encryption algorithm.
+func makeKey(t *testing.T, contentEncryption ContentEncryption) []byte {
+	key := make([]byte, contentEncryptionImplementation[contentEncryption].keySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		t.Fatalf(`Could not create key: %v`, err)
+	}
+
+	return key
+}
+
+// decodeBase64 decodes a base64 string.
+func decodeBase64(t *testing.T, s string) []byte {
+	result, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf(`Could not decode base64 string '%s': %v`, s, err)
+	}
+
+	return result
+}
+
+// decodeHex decodes a hex string.
+func decodeHex(t *testing.T, s string) []byte {
+	result, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf(`Could not decode hex string '%s': %v`, s, err)
+	}
+
+	return result
+}
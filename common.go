@@ -20,12 +20,15 @@
 
 package blockpad
 
-import "math"
+import "github.com/xformerfhs/blockpad/internal/blocksize"
 
 // ******** This file contains private data and utility functions ********
 
 // ******* Private constants ********
 
+// streamChunkBlockCount is the number of blocks that are processed in one chunk by the streaming Writer and Reader.
+const streamChunkBlockCount = 256
+
 // padImplementation holds the implementation information for the various padding algorithms.
 var padImplementation = []implementationInfo{
 	{name: `Zero`, filler: zeroFiller, remover: zeroRemover},
@@ -36,6 +39,14 @@ var padImplementation = []implementationInfo{
 	{name: `ISO 7816-4`, filler: iso78164Filler, remover: iso78164Remover},
 	{name: `Arbitrary Tail Byte`, filler: arbitraryTailByteFiller, remover: arbitraryTailBytePaddingRemover},
 	{name: `Not Last Byte`, filler: notLastBytePaddingFiller, remover: arbitraryTailBytePaddingRemover},
+	{name: `Zero (mcrypt)`, filler: mcryptZeroFiller, remover: mcryptZeroRemover, isOptional: true},
+	{
+		name:       `ISO 9797-1 method 3`,
+		filler:     iso97971M3Filler,
+		remover:    iso97971M3Remover,
+		isOptional: true,
+		hasPrefix:  true,
+	},
 }
 
 // ******** Private functions ********
@@ -56,7 +67,7 @@ func checkPadAlgorithmAndBlockSize(padAlgorithm PadAlgorithm, blockSize int) err
 
 // checkBlockSize checks if the block size is valid.
 func checkBlockSize(blockSize int) error {
-	if blockSize < 1 || blockSize > math.MaxUint8 {
+	if !blocksize.IsValid(blockSize) {
 		return ErrInvalidBlockSize
 	}
 
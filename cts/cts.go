@@ -0,0 +1,237 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cts implements the CBC mode with ciphertext stealing as specified in the
+// addendum to NIST SP 800-38A.
+//
+// With ciphertext stealing the ciphertext has the same length as the plaintext.
+// The last partial block is padded with zero bytes and encrypted in CBC mode.
+// Then the bytes of the next to last ciphertext block that are not needed for decryption are removed.
+// The data must be at least one block long.
+//
+// The three variants only differ in the order of the last two ciphertext blocks:
+//
+//   - CS1 never swaps the last two blocks.
+//   - CS2 swaps the last two blocks, if the last block is partial. It is compatible with the CBC mode for full blocks.
+//   - CS3 always swaps the last two blocks. This is the variant used by Kerberos (RFC 3962).
+package cts
+
+import (
+	"crypto/cipher"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/slicehelper"
+)
+
+// ******** Public types ********
+
+// Variant is the variant of ciphertext stealing.
+type Variant byte
+
+// Crypter encrypts and decrypts data with one block cipher and one variant.
+//
+// A Crypter is safe for concurrent use by multiple goroutines, if the block cipher is.
+type Crypter struct {
+	block   cipher.Block
+	padder  *blockpad.BlockPad
+	variant Variant
+}
+
+// ******** Public constants ********
+
+// These are the variants of ciphertext stealing.
+const (
+	CS1 Variant = iota + 1
+	CS2
+	CS3
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidVariant means that the variant is not one of CS1, CS2 or CS3.
+	ErrInvalidVariant = errors.New(`invalid ciphertext stealing variant`)
+
+	// ErrInvalidDataLen means that the data is shorter than one block.
+	ErrInvalidDataLen = errors.New(`invalid data length`)
+)
+
+// ******** Public creation function ********
+
+// NewCrypter creates a Crypter for a block cipher and a variant.
+func NewCrypter(block cipher.Block, variant Variant) (*Crypter, error) {
+	if variant < CS1 || variant > CS3 {
+		return nil, ErrInvalidVariant
+	}
+
+	// The last partial block is padded with zero bytes, but a full block is never added.
+	padder, err := blockpad.NewBlockPadding(blockpad.McryptZero, block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Crypter{block: block, padder: padder, variant: variant}, nil
+}
+
+// ******** Public functions ********
+
+// Encrypt encrypts plaintext with the initialization vector and appends the result to dst.
+// It returns the updated slice. The ciphertext has the same length as the plaintext.
+// To reuse plaintext's storage for the encrypted output, use plaintext[:0] as dst.
+// Otherwise, the remaining capacity of dst must not overlap plaintext.
+func (c *Crypter) Encrypt(dst []byte, plaintext []byte, iv []byte) ([]byte, error) {
+	blockSize := c.block.BlockSize()
+	err := c.checkParameters(len(plaintext), iv)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Pad the last block. It is empty, if the plaintext is a multiple of the block size.
+	fullBlockData, lastBlock := c.padder.PadLastBlock(plaintext)
+	fullBlockDataLen := len(fullBlockData)
+	lastBlockDataLen := len(plaintext) - fullBlockDataLen
+
+	result, out := slicehelper.ForAppend(dst, len(plaintext))
+	mode := cipher.NewCBCEncrypter(c.block, iv)
+
+	// 2. Without a partial block ciphertext stealing is the CBC mode, except for the swap of CS3.
+	if lastBlockDataLen == 0 {
+		mode.CryptBlocks(out, fullBlockData)
+		if c.variant == CS3 {
+			swapLastBlocks(out, blockSize)
+		}
+
+		return result, nil
+	}
+
+	// 3. Encrypt all blocks but the last full block directly into the destination.
+	headLen := fullBlockDataLen - blockSize
+	mode.CryptBlocks(out[:headLen], fullBlockData[:headLen])
+
+	// 4. Encrypt the last full block and the padded last block.
+	tail := make([]byte, blockSize+blockSize)
+	mode.CryptBlocks(tail[:blockSize], fullBlockData[headLen:])
+	mode.CryptBlocks(tail[blockSize:], lastBlock)
+
+	// 5. Steal the ciphertext, i.e. drop the bytes of the next to last block that are not needed.
+	stolen := tail[:lastBlockDataLen]
+	last := tail[blockSize:]
+	out = out[headLen:]
+	if c.variant == CS1 {
+		copy(out, stolen)
+		copy(out[lastBlockDataLen:], last)
+	} else {
+		copy(out, last)
+		copy(out[blockSize:], stolen)
+	}
+
+	return result, nil
+}
+
+// Decrypt decrypts ciphertext with the initialization vector and appends the result to dst.
+// It returns the updated slice. The plaintext has the same length as the ciphertext.
+// To reuse ciphertext's storage for the decrypted output, use ciphertext[:0] as dst.
+// Otherwise, the remaining capacity of dst must not overlap ciphertext.
+func (c *Crypter) Decrypt(dst []byte, ciphertext []byte, iv []byte) ([]byte, error) {
+	blockSize := c.block.BlockSize()
+	ciphertextLen := len(ciphertext)
+	err := c.checkParameters(ciphertextLen, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	result, out := slicehelper.ForAppend(dst, ciphertextLen)
+	copy(out, ciphertext)
+	mode := cipher.NewCBCDecrypter(c.block, iv)
+
+	// 1. Without a partial block ciphertext stealing is the CBC mode, except for the swap of CS3.
+	fullBlockDataLen := ciphertextLen - ciphertextLen%blockSize
+	lastBlockDataLen := ciphertextLen - fullBlockDataLen
+	if lastBlockDataLen == 0 {
+		if c.variant == CS3 {
+			swapLastBlocks(out, blockSize)
+		}
+		mode.CryptBlocks(out, out)
+
+		return result, nil
+	}
+
+	// 2. Bring the last two blocks into the order of CS1, i.e. the stolen block first.
+	headLen := fullBlockDataLen - blockSize
+	tail := make([]byte, blockSize+blockSize)
+	if c.variant == CS1 {
+		copy(tail, out[headLen:headLen+lastBlockDataLen])
+		copy(tail[blockSize:], out[headLen+lastBlockDataLen:])
+	} else {
+		copy(tail, out[headLen+blockSize:])
+		copy(tail[blockSize:], out[headLen:headLen+blockSize])
+	}
+
+	// 3. Decrypt all blocks but the last two. The mode then chains with the last of these blocks.
+	mode.CryptBlocks(out[:headLen], out[:headLen])
+
+	// 4. Decrypting the last block yields the last plaintext XOR the next to last ciphertext block.
+	// As the last plaintext block was padded with zeros, this reveals the stolen bytes.
+	last := tail[blockSize:]
+	decryptedLast := make([]byte, blockSize)
+	c.block.Decrypt(decryptedLast, last)
+	copy(tail[lastBlockDataLen:blockSize], decryptedLast[lastBlockDataLen:])
+
+	// 5. Now the last two blocks are ordinary CBC blocks.
+	mode.CryptBlocks(tail, tail)
+	copy(out[headLen:], tail[:blockSize+lastBlockDataLen])
+
+	return result, nil
+}
+
+// BlockSize returns the block size of the block cipher.
+func (c *Crypter) BlockSize() int {
+	return c.block.BlockSize()
+}
+
+// ******** Private functions ********
+
+// checkParameters checks the data length and the initialization vector.
+func (c *Crypter) checkParameters(dataLen int, iv []byte) error {
+	blockSize := c.block.BlockSize()
+	if len(iv) != blockSize {
+		return blockpad.ErrInvalidIV
+	}
+
+	if dataLen < blockSize {
+		return ErrInvalidDataLen
+	}
+
+	return nil
+}
+
+// swapLastBlocks swaps the last two blocks of data, if there are at least two blocks.
+func swapLastBlocks(data []byte, blockSize int) {
+	dataLen := len(data)
+	if dataLen < blockSize+blockSize {
+		return
+	}
+
+	last := data[dataLen-blockSize:]
+	nextToLast := data[dataLen-blockSize-blockSize : dataLen-blockSize]
+	for i := range last {
+		last[i], nextToLast[i] = nextToLast[i], last[i]
+	}
+}
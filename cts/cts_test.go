@@ -0,0 +1,246 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cts
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/hex"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// These are the key and the plaintext of the test vectors in RFC 3962, appendix B.
+const (
+	kerberosKey       = `636869636b656e207465726979616b69`
+	kerberosPlaintext = `I would like the General Gau's Chicken, please, and wonton soup.`
+)
+
+// ******** Private variables ********
+
+// kerberosTestVectors are the ciphertexts of the test vectors in RFC 3962, appendix B,
+// for the prefixes of kerberosPlaintext with the given lengths.
+// The initialization vector is zero.
+var kerberosTestVectors = []struct {
+	plaintextLen int
+	ciphertext   string
+}{
+	{17, `c6353568f2bf8cb4d8a580362da7ff7f97`},
+	{31, `fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5`},
+	{32, `39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584`},
+	{47, `97687268d6ecccc0c07b25e25ecfe584b3fffd940c16a18c1b5549d2f838029e39312523a78662d5be7fcbcc98ebf5`},
+	{48, `97687268d6ecccc0c07b25e25ecfe5849dad8bbb96c4cdc03bc103e1a194bbd839312523a78662d5be7fcbcc98ebf5a8`},
+	{64, `97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a84807efe836ee89a526730dbc2f7bc8409dad8bbb96c4cdc03bc103e1a194bbd8`},
+}
+
+// ******** Functional tests ********
+
+func TestKerberos(t *testing.T) {
+	key, _ := hex.DecodeString(kerberosKey)
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	crypter, err := NewCrypter(aesCipher, CS3)
+	if err != nil {
+		t.Fatalf(`Could not create crypter: %v`, err)
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	for _, tv := range kerberosTestVectors {
+		plaintext := []byte(kerberosPlaintext[:tv.plaintextLen])
+		expected, _ := hex.DecodeString(tv.ciphertext)
+
+		var ciphertext []byte
+		ciphertext, err = crypter.Encrypt(nil, plaintext, iv)
+		if err != nil {
+			t.Fatalf(`Encrypt failed for length %d: %v`, tv.plaintextLen, err)
+		}
+		if !bytes.Equal(ciphertext, expected) {
+			t.Fatalf(`Ciphertext for length %d is %x instead of %x`, tv.plaintextLen, ciphertext, expected)
+		}
+
+		var decrypted []byte
+		decrypted, err = crypter.Decrypt(nil, expected, iv)
+		if err != nil {
+			t.Fatalf(`Decrypt failed for length %d: %v`, tv.plaintextLen, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf(`Decrypted data for length %d is '%s' instead of '%s'`, tv.plaintextLen, decrypted, plaintext)
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	aesCipher, iv := makeAESCipher(t)
+	cs1, _ := NewCrypter(aesCipher, CS1)
+	cs2, _ := NewCrypter(aesCipher, CS2)
+	cs3, _ := NewCrypter(aesCipher, CS3)
+
+	for dataLen := aes.BlockSize; dataLen <= 5*aes.BlockSize; dataLen++ {
+		plaintext := testhelper.MakeTestSlice(dataLen)
+		lastBlockDataLen := dataLen % aes.BlockSize
+
+		c1, _ := cs1.Encrypt(nil, plaintext, iv)
+		c2, _ := cs2.Encrypt(nil, plaintext, iv)
+		c3, _ := cs3.Encrypt(nil, plaintext, iv)
+
+		// CS1 and CS2 are the CBC mode for full blocks.
+		if lastBlockDataLen == 0 {
+			expected := make([]byte, dataLen)
+			cipher.NewCBCEncrypter(aesCipher, iv).CryptBlocks(expected, plaintext)
+			if !bytes.Equal(c1, expected) || !bytes.Equal(c2, expected) {
+				t.Fatalf(`CS1 or CS2 differ from CBC for length %d`, dataLen)
+			}
+		}
+
+		// CS2 and CS3 are the same for partial blocks.
+		if lastBlockDataLen != 0 && !bytes.Equal(c2, c3) {
+			t.Fatalf(`CS2 and CS3 differ for length %d`, dataLen)
+		}
+
+		// CS3 is CS1 with the last two blocks swapped, i.e. the stolen block is at the end.
+		if dataLen > aes.BlockSize {
+			if lastBlockDataLen == 0 {
+				lastBlockDataLen = aes.BlockSize
+			}
+			stolenIndex := dataLen - aes.BlockSize - lastBlockDataLen
+			swapped := append(bytes.Clone(c1[:stolenIndex]), c1[stolenIndex+lastBlockDataLen:]...)
+			swapped = append(swapped, c1[stolenIndex:stolenIndex+lastBlockDataLen]...)
+			if !bytes.Equal(c3, swapped) {
+				t.Fatalf(`CS3 is not CS1 with swapped blocks for length %d`, dataLen)
+			}
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	desCipher, err := des.NewCipher(testhelper.MakeTestSlice(des.BlockSize))
+	if err != nil {
+		t.Fatalf(`Could not create DES cipher: %v`, err)
+	}
+	aesCipher, _ := makeAESCipher(t)
+
+	for _, block := range []cipher.Block{desCipher, aesCipher} {
+		blockSize := block.BlockSize()
+		iv := testhelper.MakeTestSlice(blockSize)
+
+		for variant := CS1; variant <= CS3; variant++ {
+			crypter, err := NewCrypter(block, variant)
+			if err != nil {
+				t.Fatalf(`Could not create crypter for variant %d: %v`, variant, err)
+			}
+
+			for dataLen := blockSize; dataLen <= 6*blockSize; dataLen++ {
+				plaintext := testhelper.MakeTestSlice(dataLen)
+
+				var ciphertext []byte
+				ciphertext, err = crypter.Encrypt(nil, plaintext, iv)
+				if err != nil {
+					t.Fatalf(`Encrypt failed for variant %d and length %d: %v`, variant, dataLen, err)
+				}
+				if len(ciphertext) != dataLen {
+					t.Fatalf(`Ciphertext length is %d instead of %d`, len(ciphertext), dataLen)
+				}
+
+				var decrypted []byte
+				decrypted, err = crypter.Decrypt(nil, ciphertext, iv)
+				if err != nil {
+					t.Fatalf(`Decrypt failed for variant %d and length %d: %v`, variant, dataLen, err)
+				}
+				if !bytes.Equal(decrypted, plaintext) {
+					t.Fatalf(`Decrypted data differs for variant %d and length %d`, variant, dataLen)
+				}
+			}
+		}
+	}
+}
+
+func TestInPlace(t *testing.T) {
+	aesCipher, iv := makeAESCipher(t)
+	crypter, _ := NewCrypter(aesCipher, CS3)
+
+	data := []byte(`Beware the ides of march`)
+	buffer := bytes.Clone(data)
+
+	ciphertext, err := crypter.Encrypt(buffer[:0], buffer, iv)
+	if err != nil {
+		t.Fatalf(`Encrypt failed: %v`, err)
+	}
+
+	var decrypted []byte
+	decrypted, err = crypter.Decrypt(ciphertext[:0], ciphertext, iv)
+	if err != nil {
+		t.Fatalf(`Decrypt failed: %v`, err)
+	}
+	if !bytes.Equal(decrypted, data) {
+		t.Fatalf(`Decrypted data '%s' is not the expected data`, decrypted)
+	}
+}
+
+// ******** Test invalid data ********
+
+func TestInvalidParameters(t *testing.T) {
+	aesCipher, iv := makeAESCipher(t)
+
+	for _, variant := range []Variant{0, CS3 + 1} {
+		_, err := NewCrypter(aesCipher, variant)
+		if !errors.Is(err, ErrInvalidVariant) {
+			t.Fatalf(`Wrong error for variant %d: %v`, variant, err)
+		}
+	}
+
+	crypter, _ := NewCrypter(aesCipher, CS1)
+
+	_, err := crypter.Encrypt(nil, testhelper.MakeTestSlice(aes.BlockSize-1), iv)
+	if !errors.Is(err, ErrInvalidDataLen) {
+		t.Fatalf(`Wrong error encrypting short data: %v`, err)
+	}
+
+	_, err = crypter.Decrypt(nil, nil, iv)
+	if !errors.Is(err, ErrInvalidDataLen) {
+		t.Fatalf(`Wrong error decrypting empty data: %v`, err)
+	}
+
+	_, err = crypter.Encrypt(nil, testhelper.MakeTestSlice(aes.BlockSize), iv[1:])
+	if !errors.Is(err, blockpad.ErrInvalidIV) {
+		t.Fatalf(`Wrong error with short initialization vector: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// makeAESCipher creates an AES cipher with a random key and a random iv.
+func makeAESCipher(t *testing.T) (cipher.Block, []byte) {
+	aesCipher, err := aes.NewCipher(testhelper.MakeTestSlice(32))
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	return aesCipher, testhelper.MakeTestSlice(aes.BlockSize)
+}
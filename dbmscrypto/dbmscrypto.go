@@ -0,0 +1,317 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dbmscrypto implements the functions ENCRYPT and DECRYPT of the Oracle package DBMS_CRYPTO
+// for RAW values.
+//
+// The cipher suite is selected with the same integer bitmask as in PL/SQL.
+// It is the sum of one encryption algorithm, one chaining mode and one padding, e.g.
+// EncryptAES256 + ChainCBC + PadPKCS5.
+//
+// PadPKCS5 is mapped to blockpad.PKCS7 and PadZero to blockpad.Zero.
+// Padding is applied in all chaining modes, so with PadNone the data must be a multiple of the block size.
+// The CFB mode uses full block segments.
+// If no initialization vector is specified, a zero initialization vector is used, as in DBMS_CRYPTO.
+package dbmscrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/blockmode"
+	"github.com/xformerfhs/blockpad/internal/slicehelper"
+)
+
+// ******** Public types ********
+
+// Typ is the cipher suite bitmask of DBMS_CRYPTO.
+type Typ uint32
+
+// ******** Public constants ********
+
+// These are the encryption algorithms.
+const (
+	EncryptDES      Typ = 1 // ENCRYPT_DES
+	Encrypt3DES2Key Typ = 2 // ENCRYPT_3DES_2KEY
+	Encrypt3DES     Typ = 3 // ENCRYPT_3DES
+	EncryptAES128   Typ = 6 // ENCRYPT_AES128
+	EncryptAES192   Typ = 7 // ENCRYPT_AES192
+	EncryptAES256   Typ = 8 // ENCRYPT_AES256
+)
+
+// These are the chaining modes.
+const (
+	ChainCBC Typ = 256  // CHAIN_CBC
+	ChainCFB Typ = 512  // CHAIN_CFB
+	ChainECB Typ = 768  // CHAIN_ECB
+	ChainOFB Typ = 1024 // CHAIN_OFB
+)
+
+// These are the paddings.
+const (
+	PadPKCS5 Typ = 4096  // PAD_PKCS5
+	PadNone  Typ = 8192  // PAD_NONE
+	PadZero  Typ = 12288 // PAD_ZERO
+)
+
+// These are the predefined cipher suites.
+const (
+	DESCBCPKCS5  = EncryptDES + ChainCBC + PadPKCS5  // DES_CBC_PKCS5
+	DES3CBCPKCS5 = Encrypt3DES + ChainCBC + PadPKCS5 // DES3_CBC_PKCS5
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidTyp means that the cipher suite is not known or not supported.
+	ErrInvalidTyp = errors.New(`invalid cipher suite`)
+
+	// ErrInvalidKeySize means that the key does not have the size that the encryption algorithm requires.
+	ErrInvalidKeySize = errors.New(`invalid key size`)
+
+	// ErrInvalidDataLen means that the data is not a multiple of the block size although it has to be.
+	ErrInvalidDataLen = errors.New(`data length is not a multiple of the block size`)
+
+	// ErrTrailingZero means that data that ends with a zero byte can not be zero padded.
+	ErrTrailingZero = errors.New(`data ends with a zero byte and can not be zero padded`)
+
+	// ErrDecryptionFailed means that the padding could not be removed after decryption.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrDecryptionFailed = errors.New(`decryption failed`)
+)
+
+// ******** Private types ********
+
+// algorithmInfo holds the parameters of an encryption algorithm.
+type algorithmInfo struct {
+	keySize  int
+	newBlock func(key []byte) (cipher.Block, error)
+}
+
+// suite holds the parsed parts of a cipher suite.
+type suite struct {
+	block   cipher.Block
+	chain   Typ
+	padding Typ
+	padder  *blockpad.BlockPad
+}
+
+// ******** Private constants ********
+
+// These are the masks of the parts of a cipher suite.
+const (
+	algorithmMask Typ = 0x00ff
+	chainMask     Typ = 0x0f00
+	padMask       Typ = 0xf000
+)
+
+// ******** Private variables ********
+
+// algorithms maps the encryption algorithms to their parameters.
+var algorithms = map[Typ]algorithmInfo{
+	EncryptDES:      {keySize: 8, newBlock: des.NewCipher},
+	Encrypt3DES2Key: {keySize: 16, newBlock: newTripleDES2KeyCipher},
+	Encrypt3DES:     {keySize: 24, newBlock: des.NewTripleDESCipher},
+	EncryptAES128:   {keySize: 16, newBlock: aes.NewCipher},
+	EncryptAES192:   {keySize: 24, newBlock: aes.NewCipher},
+	EncryptAES256:   {keySize: 32, newBlock: aes.NewCipher},
+}
+
+// padAlgorithms maps the paddings to the pad algorithms of this package.
+var padAlgorithms = map[Typ]blockpad.PadAlgorithm{
+	PadPKCS5: blockpad.PKCS7,
+	PadZero:  blockpad.Zero,
+}
+
+// ******** Public functions ********
+
+// Encrypt encrypts src like DBMS_CRYPTO.ENCRYPT(src, typ, key, iv).
+// iv may be nil, in which case a zero initialization vector is used.
+func Encrypt(src []byte, typ Typ, key []byte, iv []byte) ([]byte, error) {
+	s, err := newSuite(typ, key)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err = checkIV(iv, s.block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	data, err = s.pad(src)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, len(data))
+	s.crypt(result, data, iv, false)
+
+	return result, nil
+}
+
+// Decrypt decrypts src like DBMS_CRYPTO.DECRYPT(src, typ, key, iv).
+// iv may be nil, in which case a zero initialization vector is used.
+func Decrypt(src []byte, typ Typ, key []byte, iv []byte) ([]byte, error) {
+	s, err := newSuite(typ, key)
+	if err != nil {
+		return nil, err
+	}
+
+	blockSize := s.block.BlockSize()
+	iv, err = checkIV(iv, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(src)%blockSize != 0 {
+		return nil, ErrInvalidDataLen
+	}
+
+	result := make([]byte, len(src))
+	s.crypt(result, src, iv, true)
+
+	if s.padder == nil {
+		return result, nil
+	}
+
+	result, err = s.padder.Unpad(result)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return result, nil
+}
+
+// ******** Private functions ********
+
+// newSuite parses the cipher suite and creates the block cipher and the padder.
+func newSuite(typ Typ, key []byte) (*suite, error) {
+	if typ&^(algorithmMask|chainMask|padMask) != 0 {
+		return nil, ErrInvalidTyp
+	}
+
+	algorithm, isValidAlgorithm := algorithms[typ&algorithmMask]
+	if !isValidAlgorithm {
+		return nil, ErrInvalidTyp
+	}
+
+	chain := typ & chainMask
+	if chain != ChainCBC && chain != ChainCFB && chain != ChainECB && chain != ChainOFB {
+		return nil, ErrInvalidTyp
+	}
+
+	pad := typ & padMask
+	padAlgorithm, isPadded := padAlgorithms[pad]
+	if !isPadded && pad != PadNone {
+		return nil, ErrInvalidTyp
+	}
+
+	if len(key) != algorithm.keySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	block, err := algorithm.newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var padder *blockpad.BlockPad
+	if isPadded {
+		padder, err = blockpad.NewBlockPadding(padAlgorithm, block.BlockSize())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &suite{block: block, chain: chain, padding: pad, padder: padder}, nil
+}
+
+// pad pads the data or checks that it does not need padding.
+func (s *suite) pad(data []byte) ([]byte, error) {
+	dataLen := len(data)
+	isAligned := dataLen%s.block.BlockSize() == 0
+
+	if s.padder == nil {
+		if !isAligned {
+			return nil, ErrInvalidDataLen
+		}
+
+		return data, nil
+	}
+
+	// Zero padding can not be removed if the data ends with a zero byte in a partial block.
+	if !isAligned && data[dataLen-1] == 0 && s.padding == PadZero {
+		return nil, ErrTrailingZero
+	}
+
+	return s.padder.Pad(data), nil
+}
+
+// crypt encrypts or decrypts full blocks from src into dst in the chaining mode of the suite.
+// CHAIN_CFB and CHAIN_OFB are processed with the deprecated CFB and OFB streams of crypto/cipher.
+func (s *suite) crypt(dst []byte, src []byte, iv []byte, isDecrypter bool) {
+	switch s.chain {
+	case ChainCBC:
+		if isDecrypter {
+			cipher.NewCBCDecrypter(s.block, iv).CryptBlocks(dst, src)
+		} else {
+			cipher.NewCBCEncrypter(s.block, iv).CryptBlocks(dst, src)
+		}
+
+	case ChainECB:
+		if isDecrypter {
+			blockmode.NewECBDecrypter(s.block).CryptBlocks(dst, src)
+		} else {
+			blockmode.NewECBEncrypter(s.block).CryptBlocks(dst, src)
+		}
+
+	case ChainCFB:
+		if isDecrypter {
+			cipher.NewCFBDecrypter(s.block, iv).XORKeyStream(dst, src)
+		} else {
+			cipher.NewCFBEncrypter(s.block, iv).XORKeyStream(dst, src)
+		}
+
+	default:
+		cipher.NewOFB(s.block, iv).XORKeyStream(dst, src)
+	}
+}
+
+// checkIV checks the initialization vector and returns a zero initialization vector if it is nil.
+func checkIV(iv []byte, blockSize int) ([]byte, error) {
+	if iv == nil {
+		return make([]byte, blockSize), nil
+	}
+
+	if len(iv) != blockSize {
+		return nil, blockpad.ErrInvalidIV
+	}
+
+	return iv, nil
+}
+
+// newTripleDES2KeyCipher creates a two key triple DES cipher with the key K1 || K2 || K1.
+func newTripleDES2KeyCipher(key []byte) (cipher.Block, error) {
+	return des.NewTripleDESCipher(slicehelper.Concat(key, key[:8]))
+}
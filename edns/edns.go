@@ -0,0 +1,246 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package edns implements the EDNS(0) padding option of DNS messages (RFC 7830).
+//
+// The padding option is added to the OPT record of a wire-format DNS message,
+// so that the length of the message is a multiple of a block length (RFC 8467, block-length padding).
+package edns
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad/internal/padlen"
+	"math"
+)
+
+// ******** Public constants ********
+
+// These are the block lengths recommended by RFC 8467, section 4.1.
+const (
+	// QueryBlockLength is the recommended block length for queries.
+	QueryBlockLength = 128
+
+	// ResponseBlockLength is the recommended block length for responses.
+	ResponseBlockLength = 468
+)
+
+// ******** Private constants ********
+
+// These are the parameters of DNS messages and the EDNS(0) padding option.
+const (
+	dnsHeaderSize          = 12
+	dnsQuestionFixedSize   = 4
+	dnsRecordFixedSize     = 10
+	dnsTypeOPT             = 41
+	ednsOptionHeaderSize   = 4
+	ednsOptionCodePadding  = 12
+	dnsLabelPointerMask    = 0xc0
+	dnsLabelPointerSize    = 2
+	dnsRecordRDLengthIndex = 8
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidBlockLength means that the block length is not between 1 and 65535.
+	ErrInvalidBlockLength = errors.New(`invalid block length`)
+
+	// ErrInvalidDNSMessage means that a wire-format DNS message is malformed.
+	ErrInvalidDNSMessage = errors.New(`invalid DNS message`)
+
+	// ErrNoOPTRecord means that a DNS message does not contain an OPT record to which the padding option can be added.
+	ErrNoOPTRecord = errors.New(`DNS message has no OPT record`)
+
+	// ErrPaddingTooLong means that the padded message would exceed the maximum length of a DNS message.
+	ErrPaddingTooLong = errors.New(`padding too long`)
+)
+
+// ******** Public functions ********
+
+// Pad adds an EDNS(0) padding option (RFC 7830) to the OPT record of a wire-format DNS message,
+// so that the length of the message is a multiple of blockLength (RFC 8467, block-length padding).
+// Padding options that are already present are replaced. The padding consists of zero bytes.
+// It returns a new slice. The message must be padded before it is signed, e.g. with TSIG.
+func Pad(message []byte, blockLength int) ([]byte, error) {
+	if blockLength < 1 || blockLength > math.MaxUint16 {
+		return nil, ErrInvalidBlockLength
+	}
+
+	// 1. Remove existing padding options.
+	unpadded, err := Unpad(message)
+	if err != nil {
+		return nil, err
+	}
+
+	rdLengthIndex, _ := findOPTRecord(unpadded)
+	rdataEnd := rdLengthIndex + 2 + int(binary.BigEndian.Uint16(unpadded[rdLengthIndex:]))
+
+	// 2. Calculate the padding length. A full block of padding is not necessary.
+	_, _, padLen := padlen.Lengths(len(unpadded)+ednsOptionHeaderSize, blockLength)
+	padLen %= blockLength
+
+	optionLen := ednsOptionHeaderSize + padLen
+	resultLen := len(unpadded) + optionLen
+	newRDLength := int(binary.BigEndian.Uint16(unpadded[rdLengthIndex:])) + optionLen
+	if resultLen > math.MaxUint16 || newRDLength > math.MaxUint16 {
+		return nil, ErrPaddingTooLong
+	}
+
+	// 3. Insert the padding option at the end of the OPT record. The padding consists of the zero bytes created by make.
+	result := make([]byte, resultLen)
+	copy(result, unpadded[:rdataEnd])
+	binary.BigEndian.PutUint16(result[rdataEnd:], ednsOptionCodePadding)
+	binary.BigEndian.PutUint16(result[rdataEnd+2:], uint16(padLen))
+	copy(result[rdataEnd+optionLen:], unpadded[rdataEnd:])
+	binary.BigEndian.PutUint16(result[rdLengthIndex:], uint16(newRDLength))
+
+	return result, nil
+}
+
+// Unpad removes all EDNS(0) padding options from the OPT record of a wire-format DNS message.
+// If there is a padding option, a new slice is returned. Otherwise, the message is returned unchanged.
+func Unpad(message []byte) ([]byte, error) {
+	rdLengthIndex, err := findOPTRecord(message)
+	if err != nil {
+		return nil, err
+	}
+
+	rdataStart := rdLengthIndex + 2
+	rdataEnd := rdataStart + int(binary.BigEndian.Uint16(message[rdLengthIndex:]))
+
+	// 1. Copy all options except the padding options.
+	var options []byte
+	hasPadding := false
+	for i := rdataStart; i < rdataEnd; {
+		if rdataEnd-i < ednsOptionHeaderSize {
+			return nil, ErrInvalidDNSMessage
+		}
+
+		optionEnd := i + ednsOptionHeaderSize + int(binary.BigEndian.Uint16(message[i+2:]))
+		if optionEnd > rdataEnd {
+			return nil, ErrInvalidDNSMessage
+		}
+
+		if binary.BigEndian.Uint16(message[i:]) == ednsOptionCodePadding {
+			hasPadding = true
+		} else {
+			options = append(options, message[i:optionEnd]...)
+		}
+
+		i = optionEnd
+	}
+
+	if !hasPadding {
+		return message, nil
+	}
+
+	// 2. Build the message with the remaining options.
+	result := make([]byte, 0, len(message)-(rdataEnd-rdataStart)+len(options))
+	result = append(result, message[:rdataStart]...)
+	result = append(result, options...)
+	result = append(result, message[rdataEnd:]...)
+	binary.BigEndian.PutUint16(result[rdLengthIndex:], uint16(len(options)))
+
+	return result, nil
+}
+
+// ******** Private functions ********
+
+// findOPTRecord walks through all sections of a DNS message and returns the index of the RDLENGTH field of the OPT record.
+func findOPTRecord(message []byte) (int, error) {
+	messageLen := len(message)
+	if messageLen < dnsHeaderSize {
+		return 0, ErrInvalidDNSMessage
+	}
+
+	questionCount := int(binary.BigEndian.Uint16(message[4:]))
+	recordCount := int(binary.BigEndian.Uint16(message[6:])) +
+		int(binary.BigEndian.Uint16(message[8:])) +
+		int(binary.BigEndian.Uint16(message[10:]))
+
+	// 1. Skip the questions.
+	index := dnsHeaderSize
+	var err error
+	for i := 0; i < questionCount; i++ {
+		index, err = skipDNSName(message, index)
+		if err != nil {
+			return 0, err
+		}
+
+		index += dnsQuestionFixedSize
+		if index > messageLen {
+			return 0, ErrInvalidDNSMessage
+		}
+	}
+
+	// 2. Look for the OPT record in all resource records.
+	for i := 0; i < recordCount; i++ {
+		index, err = skipDNSName(message, index)
+		if err != nil {
+			return 0, err
+		}
+
+		if messageLen-index < dnsRecordFixedSize {
+			return 0, ErrInvalidDNSMessage
+		}
+
+		rdLengthIndex := index + dnsRecordRDLengthIndex
+		index += dnsRecordFixedSize + int(binary.BigEndian.Uint16(message[rdLengthIndex:]))
+		if index > messageLen {
+			return 0, ErrInvalidDNSMessage
+		}
+
+		if binary.BigEndian.Uint16(message[rdLengthIndex-dnsRecordRDLengthIndex:]) == dnsTypeOPT {
+			return rdLengthIndex, nil
+		}
+	}
+
+	return 0, ErrNoOPTRecord
+}
+
+// skipDNSName returns the index after the domain name that starts at index.
+func skipDNSName(message []byte, index int) (int, error) {
+	messageLen := len(message)
+
+	for index < messageLen {
+		labelLen := int(message[index])
+
+		switch {
+		case labelLen == 0:
+			return index + 1, nil
+
+		case labelLen&dnsLabelPointerMask == dnsLabelPointerMask:
+			if messageLen-index < dnsLabelPointerSize {
+				return 0, ErrInvalidDNSMessage
+			}
+
+			return index + dnsLabelPointerSize, nil
+
+		case labelLen&dnsLabelPointerMask != 0:
+			return 0, ErrInvalidDNSMessage
+
+		default:
+			index += 1 + labelLen
+		}
+	}
+
+	return 0, ErrInvalidDNSMessage
+}
@@ -0,0 +1,201 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edns
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the number of block lengths in the functional tests.
+const loopCount = 100
+
+// These are wire-format DNS messages for the tests.
+const (
+	// dnsQuery is a query for "example.com" type A with an empty OPT record.
+	dnsQuery = `123401000001000000000001` +
+		`076578616d706c6503636f6d0000010001` +
+		`0000291000000000000000`
+
+	// dnsResponse is a response with a compressed answer, an OPT record with a cookie option
+	// and a record after the OPT record.
+	dnsResponse = `123481800001000100000002` +
+		`076578616d706c6503636f6d0000010001` +
+		`c00c000100010000012c00045db8d822` +
+		`000029100000000000000c000a00080102030405060708` +
+		`c00c001000010000012c000403616263`
+)
+
+// ******** Functional tests ********
+
+func TestPadQuery(t *testing.T) {
+	message := decodeDNSMessage(t, dnsQuery)
+
+	padded, err := Pad(message, QueryBlockLength)
+	if err != nil {
+		t.Fatalf(`Pad failed: %v`, err)
+	}
+	if len(padded) != QueryBlockLength {
+		t.Fatalf(`Wrong padded length %d`, len(padded))
+	}
+
+	// The padding option follows the OPT record fields and contains only zero bytes.
+	optionStart := len(message)
+	if binary.BigEndian.Uint16(padded[optionStart:]) != 12 ||
+		int(binary.BigEndian.Uint16(padded[optionStart+2:])) != QueryBlockLength-len(message)-4 ||
+		!bytes.Equal(padded[optionStart+4:], make([]byte, QueryBlockLength-len(message)-4)) {
+		t.Fatalf(`Wrong padding option: %x`, padded[optionStart:])
+	}
+	if int(binary.BigEndian.Uint16(padded[optionStart-2:])) != QueryBlockLength-len(message) {
+		t.Fatalf(`Wrong RDLENGTH of OPT record`)
+	}
+
+	var unpadded []byte
+	unpadded, err = Unpad(padded)
+	if err != nil {
+		t.Fatalf(`Unpad failed: %v`, err)
+	}
+	if !bytes.Equal(unpadded, message) {
+		t.Fatalf(`Unpadded message differs from message: %x`, unpadded)
+	}
+}
+
+func TestPadResponse(t *testing.T) {
+	message := decodeDNSMessage(t, dnsResponse)
+
+	padded, err := Pad(message, ResponseBlockLength)
+	if err != nil {
+		t.Fatalf(`Pad failed: %v`, err)
+	}
+	if len(padded) != ResponseBlockLength {
+		t.Fatalf(`Wrong padded length %d`, len(padded))
+	}
+
+	// The record after the OPT record must be unchanged.
+	if !bytes.HasSuffix(padded, message[len(message)-16:]) {
+		t.Fatal(`Record after OPT record has been changed`)
+	}
+
+	// Padding a padded message replaces the padding option.
+	var repadded []byte
+	repadded, err = Pad(padded, 64)
+	if err != nil {
+		t.Fatalf(`Pad of padded message failed: %v`, err)
+	}
+	if len(repadded)%64 != 0 || len(repadded) >= len(message)+4+64 {
+		t.Fatalf(`Wrong length %d of repadded message`, len(repadded))
+	}
+
+	var unpadded []byte
+	unpadded, err = Unpad(repadded)
+	if err != nil {
+		t.Fatalf(`Unpad failed: %v`, err)
+	}
+	if !bytes.Equal(unpadded, message) {
+		t.Fatalf(`Unpadded message differs from message: %x`, unpadded)
+	}
+}
+
+func TestPadAllLengths(t *testing.T) {
+	message := decodeDNSMessage(t, dnsQuery)
+
+	for blockLength := 1; blockLength <= loopCount; blockLength++ {
+		padded, err := Pad(message, blockLength)
+		if err != nil {
+			t.Fatalf(`Pad with block length %d failed: %v`, blockLength, err)
+		}
+		if len(padded)%blockLength != 0 || len(padded) >= len(message)+4+blockLength {
+			t.Fatalf(`Wrong padded length %d with block length %d`, len(padded), blockLength)
+		}
+	}
+}
+
+func TestUnpadWithoutPadding(t *testing.T) {
+	message := decodeDNSMessage(t, dnsResponse)
+
+	unpadded, err := Unpad(message)
+	if err != nil {
+		t.Fatalf(`Unpad failed: %v`, err)
+	}
+	if !bytes.Equal(unpadded, message) {
+		t.Fatal(`Message without padding has been changed`)
+	}
+}
+
+// ******** Error tests ********
+
+func TestPadInvalidMessages(t *testing.T) {
+	query := decodeDNSMessage(t, dnsQuery)
+
+	noOPT := bytes.Clone(query[:len(query)-11])
+	noOPT[11] = 0
+	_, err := Pad(noOPT, QueryBlockLength)
+	if !errors.Is(err, ErrNoOPTRecord) {
+		t.Fatalf(`Missing OPT record was not detected: %v`, err)
+	}
+
+	for _, message := range [][]byte{
+		query[:11],
+		query[:20],
+		query[:len(query)-1],
+	} {
+		_, err = Pad(message, QueryBlockLength)
+		if !errors.Is(err, ErrInvalidDNSMessage) {
+			t.Fatalf(`Truncated message of length %d was not detected: %v`, len(message), err)
+		}
+	}
+
+	invalidLabel := bytes.Clone(query)
+	invalidLabel[dnsHeaderSize] = 0x47
+	_, err = Pad(invalidLabel, QueryBlockLength)
+	if !errors.Is(err, ErrInvalidDNSMessage) {
+		t.Fatalf(`Invalid label type was not detected: %v`, err)
+	}
+
+	invalidOption := append(bytes.Clone(query), 0, 12)
+	binary.BigEndian.PutUint16(invalidOption[len(invalidOption)-4:], 2)
+	_, err = Unpad(invalidOption)
+	if !errors.Is(err, ErrInvalidDNSMessage) {
+		t.Fatalf(`Truncated option was not detected: %v`, err)
+	}
+
+	_, err = Pad(query, 0)
+	if !errors.Is(err, ErrInvalidBlockLength) {
+		t.Fatalf(`Invalid block length was not detected: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// decodeDNSMessage decodes a hex encoded DNS message.
+func decodeDNSMessage(t *testing.T, s string) []byte {
+	result, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf(`Could not decode DNS message: %v`, err)
+	}
+
+	return result
+}
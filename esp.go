@@ -0,0 +1,140 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+// ******** This file contains the IPsec ESP trailer ********
+
+// ******** Public types ********
+
+// ESPTrailer builds and removes the trailer of IPsec ESP packets as specified in RFC 4303, sections 2.4 to 2.7:
+//
+//	payload || TFC padding || padding (1, 2, 3, ...) || pad length || next header
+//
+// The padding consists of 0 to 255 bytes, so that the length of the whole packet is a multiple of the cipher block size
+// and of 4 bytes, even for stream ciphers. The padding bytes are created and checked with the RFC4303 pad algorithm.
+//
+// An ESPTrailer is safe for concurrent use by multiple goroutines, as it is used read-only.
+type ESPTrailer struct {
+	alignment int
+}
+
+// ******** Public constants ********
+
+// ESPNoNextHeader is the next header value of dummy packets (RFC 4303, section 2.6).
+const ESPNoNextHeader byte = 59
+
+// ******** Private constants ********
+
+// These are the ESP trailer parameters.
+const (
+	espTrailerSize  = 2
+	espMinAlignment = 4
+	espMaxPadLen    = 255
+)
+
+// ******** Public creation function ********
+
+// NewESPTrailer creates an ESP trailer for a cipher block size.
+// For stream ciphers the block size is 1.
+func NewESPTrailer(cipherBlockSize int) (*ESPTrailer, error) {
+	err := checkBlockSize(cipherBlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// The alignment is the least common multiple of 4 and the block size.
+	alignment := cipherBlockSize
+	for alignment%espMinAlignment != 0 {
+		alignment += cipherBlockSize
+	}
+
+	// The padding needs up to alignment - 1 bytes, which must fit into the pad length byte.
+	if alignment-1 > espMaxPadLen {
+		return nil, ErrInvalidBlockSize
+	}
+
+	return &ESPTrailer{alignment: alignment}, nil
+}
+
+// ******** Public functions ********
+
+// Pad appends tfcPadLen zero bytes of traffic flow confidentiality padding, the padding, the pad length
+// and the next header to a copy of the payload.
+// It panics if tfcPadLen is negative.
+func (et *ESPTrailer) Pad(payload []byte, nextHeader byte, tfcPadLen int) []byte {
+	if tfcPadLen < 0 {
+		panic(`TFC padding length must not be negative`)
+	}
+
+	alignment := et.alignment
+
+	// 1. Calculate the lengths.
+	dataLen := len(payload) + tfcPadLen
+	padLen := (alignment - (dataLen+espTrailerSize)%alignment) % alignment
+
+	// 2. Build the packet. The TFC padding consists of the zero bytes created by make.
+	result := make([]byte, dataLen+padLen+espTrailerSize)
+	copy(result, payload)
+	rfc4303Filler(nil, result[dataLen:dataLen+padLen], padLen, nil, 0, padLen, dataLen)
+	result[dataLen+padLen] = byte(padLen)
+	result[dataLen+padLen+1] = nextHeader
+
+	return result
+}
+
+// Unpad checks and removes the ESP trailer of decrypted data.
+// It returns a byte slice into the supplied data and the next header value.
+// TFC padding can not be distinguished from the payload. It is part of the returned data
+// and has to be removed with the length information of the next protocol.
+// It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle,
+// if the data have not been authenticated before.
+func (et *ESPTrailer) Unpad(data []byte) ([]byte, byte, error) {
+	// 1. Check the length.
+	dataLen := len(data)
+	if dataLen == 0 || dataLen%et.alignment != 0 {
+		return nil, 0, ErrInvalidPaddedDataLen
+	}
+
+	trailerStart := dataLen - espTrailerSize
+	padLenByte := data[trailerStart]
+	nextHeader := data[trailerStart+1]
+
+	if padLenByte == 0 {
+		return data[:trailerStart], nextHeader, nil
+	}
+
+	if int(padLenByte) > trailerStart {
+		return nil, 0, ErrInvalidPadding
+	}
+
+	// 2. The last padding byte has the value of the pad length, so it can be checked by rfc4303Remover.
+	unpaddedData, err := rfc4303Remover(nil, data[:trailerStart], trailerStart, trailerStart, min(espMaxPadLen, trailerStart))
+	if err != nil || data[trailerStart-1] != padLenByte {
+		return nil, 0, ErrInvalidPadding
+	}
+
+	return unpaddedData, nextHeader, nil
+}
+
+// Alignment returns the value of which the length of all padded packets is a multiple.
+func (et *ESPTrailer) Alignment() int {
+	return et.alignment
+}
@@ -0,0 +1,143 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// ******** Functional tests ********
+
+func TestESPTrailerVector(t *testing.T) {
+	trailer, err := NewESPTrailer(16)
+	if err != nil {
+		t.Fatalf(`Error creating ESP trailer: %v`, err)
+	}
+
+	// 3 bytes of payload, 11 bytes of padding, pad length 11 and next header 4 (IPv4).
+	padded := trailer.Pad([]byte(`abc`), 4, 0)
+	if hex.EncodeToString(padded) != `6162630102030405060708090a0b0b04` {
+		t.Fatalf(`Wrong ESP trailer: %x`, padded)
+	}
+
+	payload, nextHeader, err := trailer.Unpad(padded)
+	if err != nil {
+		t.Fatalf(`Unpad failed: %v`, err)
+	}
+	if string(payload) != `abc` || nextHeader != 4 {
+		t.Fatalf(`Wrong payload '%s' or next header %d`, payload, nextHeader)
+	}
+}
+
+func TestESPTrailerAll(t *testing.T) {
+	for _, cipherBlockSize := range []int{1, 6, 8, 16} {
+		trailer, err := NewESPTrailer(cipherBlockSize)
+		if err != nil {
+			t.Fatalf(`Error creating ESP trailer with block size %d: %v`, cipherBlockSize, err)
+		}
+
+		alignment := trailer.Alignment()
+		if alignment%4 != 0 || alignment%cipherBlockSize != 0 {
+			t.Fatalf(`Wrong alignment %d for block size %d`, alignment, cipherBlockSize)
+		}
+
+		for payloadLen := 0; payloadLen <= loopCount; payloadLen++ {
+			payload := makeTestSlice(payloadLen)
+
+			for _, tfcPadLen := range []int{0, 5, 32} {
+				padded := trailer.Pad(payload, 41, tfcPadLen)
+				if len(padded)%alignment != 0 {
+					t.Fatalf(`Padded length %d is not a multiple of %d`, len(padded), alignment)
+				}
+
+				unpadded, nextHeader, err := trailer.Unpad(padded)
+				if err != nil {
+					t.Fatalf(`Unpad with payload length %d failed: %v`, payloadLen, err)
+				}
+				if nextHeader != 41 {
+					t.Fatalf(`Wrong next header %d`, nextHeader)
+				}
+				if !bytes.Equal(unpadded[:payloadLen], payload) || !bytes.Equal(unpadded[payloadLen:], make([]byte, tfcPadLen)) {
+					t.Fatalf(`Unpadded data differ from payload and TFC padding with payload length %d`, payloadLen)
+				}
+			}
+		}
+	}
+}
+
+// ******** Error tests ********
+
+func TestESPTrailerInvalidPadding(t *testing.T) {
+	trailer, _ := NewESPTrailer(16)
+	padded := trailer.Pad([]byte(`abcdef`), ESPNoNextHeader, 0)
+
+	// Wrong padding byte.
+	modified := bytes.Clone(padded)
+	modified[7] = 99
+	_, _, err := trailer.Unpad(modified)
+	if !errors.Is(err, ErrInvalidPadding) {
+		t.Fatalf(`Wrong padding byte was not detected: %v`, err)
+	}
+
+	// Pad length that does not match the last padding byte.
+	modified = bytes.Clone(padded)
+	modified[len(modified)-2] = 7
+	_, _, err = trailer.Unpad(modified)
+	if !errors.Is(err, ErrInvalidPadding) {
+		t.Fatalf(`Wrong pad length was not detected: %v`, err)
+	}
+
+	// Pad length that is longer than the data.
+	modified = bytes.Clone(padded)
+	modified[len(modified)-2] = 15
+	_, _, err = trailer.Unpad(modified)
+	if !errors.Is(err, ErrInvalidPadding) {
+		t.Fatalf(`Too large pad length was not detected: %v`, err)
+	}
+
+	_, _, err = trailer.Unpad(padded[:len(padded)-1])
+	if !errors.Is(err, ErrInvalidPaddedDataLen) {
+		t.Fatalf(`Wrong data length was not detected: %v`, err)
+	}
+}
+
+func TestESPTrailerNegativeTFCPadLen(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`No panic with negative TFC padding length`)
+		}
+	}()
+
+	trailer, _ := NewESPTrailer(16)
+	_ = trailer.Pad([]byte(`payload`), ESPNoNextHeader, -1)
+}
+
+func TestESPTrailerInvalidBlockSize(t *testing.T) {
+	for _, cipherBlockSize := range []int{0, 255, 256} {
+		_, err := NewESPTrailer(cipherBlockSize)
+		if !errors.Is(err, ErrInvalidBlockSize) {
+			t.Fatalf(`Invalid block size %d was not detected: %v`, cipherBlockSize, err)
+		}
+	}
+}
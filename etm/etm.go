@@ -0,0 +1,198 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package etm implements an encrypt-then-MAC construction as a [crypto/cipher/AEAD].
+//
+// The data is padded, encrypted in CBC mode with a random initialization vector
+// and then authenticated with an HMAC over the additional data, the initialization vector,
+// the ciphertext and the lengths of the additional data and the ciphertext.
+//
+// On decryption the HMAC is verified in constant time before the padding is removed.
+// So a padding error can never be observed by an attacker and there is no padding oracle.
+package etm
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/slicehelper"
+	"github.com/xformerfhs/blockpad/padmode"
+	"hash"
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrAuthenticationFailed means that the message could not be authenticated.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrAuthenticationFailed = errors.New(`message authentication failed`)
+
+	// ErrInvalidMACKey means that the MAC key is empty.
+	ErrInvalidMACKey = errors.New(`invalid MAC key`)
+)
+
+// ******** Private types ********
+
+// encryptThenMAC holds the data necessary for the encrypt-then-MAC construction.
+type encryptThenMAC struct {
+	block     cipher.Block
+	padder    *blockpad.BlockPad
+	hashFunc  func() hash.Hash
+	macKey    []byte
+	blockSize int
+	tagSize   int
+
+	// minPaddedLen is the length of padded empty data.
+	// It is 0 for optional paddings that do not add a block to block-aligned data.
+	minPaddedLen int
+}
+
+// ******** Public creation function ********
+
+// NewAEAD creates a [crypto/cipher/AEAD] from a block cipher, a pad algorithm,
+// a hash constructor, e.g. sha256.New, and a MAC key.
+// The MAC key must be independent of the encryption key of the block cipher.
+//
+// The initialization vector is created randomly on every call of Seal and prepended to the ciphertext.
+// Therefore, the returned AEAD has a nonce size of 0 and the nonce passed to Seal and Open must be empty.
+func NewAEAD(block cipher.Block, padAlgorithm blockpad.PadAlgorithm, hashFunc func() hash.Hash, macKey []byte) (cipher.AEAD, error) {
+	if len(macKey) == 0 {
+		return nil, ErrInvalidMACKey
+	}
+
+	blockSize := block.BlockSize()
+	padder, err := blockpad.NewBlockPadding(padAlgorithm, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptThenMAC{
+		block:        block,
+		padder:       padder,
+		hashFunc:     hashFunc,
+		macKey:       append([]byte(nil), macKey...),
+		blockSize:    blockSize,
+		tagSize:      hashFunc().Size(),
+		minPaddedLen: len(padder.Pad(nil)),
+	}, nil
+}
+
+// ******** Public functions ********
+
+// NonceSize returns 0, as the initialization vector is created randomly and is part of the ciphertext.
+func (e *encryptThenMAC) NonceSize() int {
+	return 0
+}
+
+// Overhead returns the maximum difference between the lengths of a plaintext and its ciphertext.
+// This is the length of the initialization vector, a full padding block, a prefix block, if the padding has one, and the tag.
+func (e *encryptThenMAC) Overhead() int {
+	return e.blockSize + e.padder.PrefixLen() + e.blockSize + e.tagSize
+}
+
+// Seal pads, encrypts and authenticates plaintext, authenticates the
+// additional data and appends the result to dst, returning the updated slice.
+// The result consists of the initialization vector, the ciphertext and the tag.
+// The nonce must be empty and dst must not overlap plaintext.
+func (e *encryptThenMAC) Seal(dst []byte, nonce []byte, plaintext []byte, additionalData []byte) []byte {
+	if len(nonce) != 0 {
+		panic(`etm: nonce must be empty`)
+	}
+
+	dstLen := len(dst)
+
+	// 1. Create a random initialization vector.
+	result, iv := slicehelper.ForAppend(dst, e.blockSize)
+	_, err := rand.Read(iv)
+	if err != nil {
+		panic(`etm: could not create initialization vector: ` + err.Error())
+	}
+
+	// 2. Pad and encrypt.
+	encrypter, _ := padmode.NewPaddedEncrypter(cipher.NewCBCEncrypter(e.block, iv), e.padder)
+	result = encrypter.Seal(result, plaintext)
+
+	// 3. Authenticate.
+	return e.appendTag(result, additionalData, result[dstLen:])
+}
+
+// Open authenticates and decrypts ciphertext, authenticates the
+// additional data and, if successful, appends the resulting plaintext
+// to dst, returning the updated slice.
+// The nonce must be empty and dst must not overlap ciphertext.
+//
+// The tag is verified in constant time before the padding is removed.
+// Any error is reported as ErrAuthenticationFailed.
+func (e *encryptThenMAC) Open(dst []byte, nonce []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 0 {
+		panic(`etm: nonce must be empty`)
+	}
+
+	blockSize := e.blockSize
+
+	// 1. Check the length. There must be an iv, the padded data and a tag.
+	// Padded data consist of at least one block, unless the padding is optional.
+	ivAndCiphertextLen := len(ciphertext) - e.tagSize
+	if ivAndCiphertextLen < blockSize+e.minPaddedLen || ivAndCiphertextLen%blockSize != 0 {
+		return nil, ErrAuthenticationFailed
+	}
+
+	ivAndCiphertext := ciphertext[:ivAndCiphertextLen]
+	tag := ciphertext[ivAndCiphertextLen:]
+
+	// 2. Verify the tag before anything else is done with the ciphertext.
+	expectedTag := e.appendTag(make([]byte, 0, e.tagSize), additionalData, ivAndCiphertext)
+	if !hmac.Equal(tag, expectedTag) {
+		return nil, ErrAuthenticationFailed
+	}
+
+	// 3. Decrypt and unpad.
+	iv := ivAndCiphertext[:blockSize]
+	decrypter, _ := padmode.NewPaddedDecrypter(cipher.NewCBCDecrypter(e.block, iv), e.padder)
+	result, err := decrypter.Open(dst, ivAndCiphertext[blockSize:])
+	if err != nil {
+		// This can only happen if the sender used a different padding.
+		return nil, ErrAuthenticationFailed
+	}
+
+	return result, nil
+}
+
+// ******** Private functions ********
+
+// appendTag calculates the tag over the additional data, the initialization vector,
+// the ciphertext and the lengths of the additional data and the ciphertext
+// and appends it to dst.
+func (e *encryptThenMAC) appendTag(dst []byte, additionalData []byte, ivAndCiphertext []byte) []byte {
+	mac := hmac.New(e.hashFunc, e.macKey)
+	mac.Write(additionalData)
+	mac.Write(ivAndCiphertext)
+
+	var lengths [16]byte
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(additionalData)))
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ivAndCiphertext)))
+	mac.Write(lengths[:])
+
+	return mac.Sum(dst)
+}
@@ -0,0 +1,187 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	mrand "math/rand"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the number of times a functional test is to be performed.
+const loopCount = 100
+
+// ******** Functional tests ********
+
+func TestSealOpen(t *testing.T) {
+	aead := makeAEAD(t, blockpad.PKCS7)
+
+	for i := 0; i < loopCount; i++ {
+		data := testhelper.MakeTestSlice(mrand.Intn(200))
+		additionalData := testhelper.MakeTestSlice(mrand.Intn(50))
+		prefix := testhelper.MakeTestSlice(mrand.Intn(5))
+
+		sealed := aead.Seal(prefix, nil, data, additionalData)
+		if !bytes.Equal(sealed[:len(prefix)], prefix) {
+			t.Fatal(`Seal did not keep dst`)
+		}
+
+		sealedLen := len(sealed) - len(prefix)
+		if sealedLen > len(data)+aead.Overhead() {
+			t.Fatalf(`Sealed length %d exceeds overhead for data length %d`, sealedLen, len(data))
+		}
+
+		opened, err := aead.Open(nil, nil, sealed[len(prefix):], additionalData)
+		if err != nil {
+			t.Fatalf(`Open failed: %v`, err)
+		}
+		if !bytes.Equal(opened, data) {
+			t.Fatal(`Opened data differs from data`)
+		}
+	}
+}
+
+func TestRandomIV(t *testing.T) {
+	aead := makeAEAD(t, blockpad.PKCS7)
+	data := []byte(`Beware the ides of march`)
+
+	if bytes.Equal(aead.Seal(nil, nil, data, nil), aead.Seal(nil, nil, data, nil)) {
+		t.Fatal(`Two encryptions of the same data yielded the same result`)
+	}
+}
+
+func TestSealOpenEmptyOptionalPadding(t *testing.T) {
+	aead := makeAEAD(t, blockpad.McryptZero)
+
+	// An optional padding does not add a block, so only the iv and the tag are sealed.
+	sealed := aead.Seal(nil, nil, nil, nil)
+	if len(sealed) != aes.BlockSize+sha256.Size {
+		t.Fatalf(`Sealed length of empty data is %d`, len(sealed))
+	}
+
+	opened, err := aead.Open(nil, nil, sealed, nil)
+	if err != nil {
+		t.Fatalf(`Open of empty data failed: %v`, err)
+	}
+	if len(opened) != 0 {
+		t.Fatalf(`Opened empty data has length %d`, len(opened))
+	}
+}
+
+// ******** Test manipulated data ********
+
+func TestTamperedData(t *testing.T) {
+	aead := makeAEAD(t, blockpad.PKCS7)
+	data := []byte(`Cryptography is fun`)
+	additionalData := []byte(`header`)
+
+	sealed := aead.Seal(nil, nil, data, additionalData)
+
+	for i := range sealed {
+		tampered := bytes.Clone(sealed)
+		tampered[i] ^= 0x01
+
+		_, err := aead.Open(nil, nil, tampered, additionalData)
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf(`Wrong error opening data tampered at index %d: %v`, i, err)
+		}
+	}
+
+	_, err := aead.Open(nil, nil, sealed, []byte(`other header`))
+	if !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf(`Wrong error opening data with wrong additional data: %v`, err)
+	}
+}
+
+func TestNoPaddingOracle(t *testing.T) {
+	aead := makeAEAD(t, blockpad.PKCS7)
+	sealed := aead.Seal(nil, nil, []byte(`Padding oracle`), nil)
+
+	// Manipulate the padding in the last block by changing the previous ciphertext block.
+	tagSize := sha256.Size
+	previousBlockIndex := len(sealed) - tagSize - 2*aes.BlockSize
+	sealed[previousBlockIndex+aes.BlockSize-1] ^= 0xff
+
+	_, err := aead.Open(nil, nil, sealed, nil)
+	if errors.Is(err, blockpad.ErrInvalidPadding) {
+		t.Fatal(`Padding error is visible`)
+	}
+	if !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf(`Wrong error opening data with manipulated padding: %v`, err)
+	}
+}
+
+func TestTruncatedData(t *testing.T) {
+	aead := makeAEAD(t, blockpad.PKCS7)
+	sealed := aead.Seal(nil, nil, []byte(`Truncated`), nil)
+
+	for _, sealedLen := range []int{0, 1, aes.BlockSize, len(sealed) - 1} {
+		_, err := aead.Open(nil, nil, sealed[:sealedLen], nil)
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf(`Wrong error opening data truncated to %d bytes: %v`, sealedLen, err)
+		}
+	}
+}
+
+// ******** Test invalid parameters ********
+
+func TestInvalidParameters(t *testing.T) {
+	aesCipher, err := aes.NewCipher(testhelper.MakeTestSlice(32))
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	_, err = NewAEAD(aesCipher, blockpad.PKCS7, sha256.New, nil)
+	if !errors.Is(err, ErrInvalidMACKey) {
+		t.Fatalf(`Wrong error creating AEAD with empty MAC key: %v`, err)
+	}
+
+	_, err = NewAEAD(aesCipher, 255, sha256.New, testhelper.MakeTestSlice(32))
+	if !errors.Is(err, blockpad.ErrInvalidPadAlgorithm) {
+		t.Fatalf(`Wrong error creating AEAD with invalid pad algorithm: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// makeAEAD creates an AES-CBC-HMAC-SHA256 AEAD with random keys.
+func makeAEAD(t *testing.T, padAlgorithm blockpad.PadAlgorithm) cipher.AEAD {
+	aesCipher, err := aes.NewCipher(testhelper.MakeTestSlice(32))
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	var aead cipher.AEAD
+	aead, err = NewAEAD(aesCipher, padAlgorithm, sha256.New, testhelper.MakeTestSlice(32))
+	if err != nil {
+		t.Fatalf(`Could not create AEAD: %v`, err)
+	}
+
+	return aead
+}
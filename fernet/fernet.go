@@ -0,0 +1,253 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fernet implements Fernet tokens as specified in https://github.com/fernet/spec.
+//
+// A Fernet token consists of a version byte, a timestamp, an initialization vector,
+// the AES-128-CBC encrypted data with PKCS#7 padding and an HMAC-SHA256 over all these parts.
+// The token is encoded with URL-safe base64.
+// Tokens are compatible with the Python reference implementation.
+package fernet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/padmode"
+	"time"
+)
+
+// ******** Public types ********
+
+// Fernet encrypts and decrypts Fernet tokens with one key.
+//
+// A Fernet is safe for concurrent use by multiple goroutines, as it is used read-only.
+type Fernet struct {
+	signingKey []byte
+	block      cipher.Block
+	padder     *blockpad.BlockPad
+}
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidKey means that the key is not a base64url encoded 32 byte value.
+	ErrInvalidKey = errors.New(`invalid Fernet key`)
+
+	// ErrInvalidToken means that the token is invalid.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrInvalidToken = errors.New(`invalid Fernet token`)
+)
+
+// ******** Private constants ********
+
+// version is the version byte of a Fernet token.
+const version byte = 0x80
+
+// keySize is the size of a Fernet key. It consists of the signing key and the encryption key.
+const keySize = 32
+
+// halfKeySize is the size of the signing key and the encryption key.
+const halfKeySize = keySize >> 1
+
+// maxClockSkew is the maximum time a token timestamp may lie in the future.
+const maxClockSkew = 60 * time.Second
+
+// These are the offsets of the token parts.
+const (
+	timestampOffset  = 1
+	ivOffset         = timestampOffset + 8
+	ciphertextOffset = ivOffset + aes.BlockSize
+)
+
+// minTokenLen is the length of a token with one ciphertext block.
+const minTokenLen = ciphertextOffset + aes.BlockSize + sha256.Size
+
+// ******** Public creation functions ********
+
+// GenerateKey creates a new random base64url encoded Fernet key.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return ``, err
+	}
+
+	return base64.URLEncoding.EncodeToString(key), nil
+}
+
+// NewFernet creates a Fernet from a base64url encoded key.
+func NewFernet(key string) (*Fernet, error) {
+	rawKey, err := base64.URLEncoding.DecodeString(key)
+	if err != nil || len(rawKey) != keySize {
+		return nil, ErrInvalidKey
+	}
+
+	var block cipher.Block
+	block, err = aes.NewCipher(rawKey[halfKeySize:])
+	if err != nil {
+		return nil, err
+	}
+
+	var padder *blockpad.BlockPad
+	padder, err = blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fernet{
+		signingKey: rawKey[:halfKeySize],
+		block:      block,
+		padder:     padder,
+	}, nil
+}
+
+// ******** Public functions ********
+
+// Encrypt encrypts data into a token with the current time as timestamp.
+func (f *Fernet) Encrypt(data []byte) ([]byte, error) {
+	return f.EncryptAtTime(data, time.Now())
+}
+
+// EncryptAtTime encrypts data into a token with the supplied time as timestamp.
+func (f *Fernet) EncryptAtTime(data []byte, now time.Time) ([]byte, error) {
+	iv := make([]byte, aes.BlockSize)
+	_, err := rand.Read(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.encrypt(data, now, iv), nil
+}
+
+// Decrypt verifies a token and returns the decrypted data.
+// If ttl is greater than 0, tokens that are older than ttl are rejected.
+func (f *Fernet) Decrypt(token []byte, ttl time.Duration) ([]byte, error) {
+	return f.DecryptAtTime(token, ttl, time.Now())
+}
+
+// DecryptAtTime verifies a token with respect to the supplied time and returns the decrypted data.
+// If ttl is greater than 0, tokens that are older than ttl are rejected.
+func (f *Fernet) DecryptAtTime(token []byte, ttl time.Duration, now time.Time) ([]byte, error) {
+	rawToken, timestamp, err := f.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Check the timestamp.
+	if timestamp.After(now.Add(maxClockSkew)) {
+		return nil, ErrInvalidToken
+	}
+
+	if ttl > 0 && timestamp.Add(ttl).Before(now) {
+		return nil, ErrInvalidToken
+	}
+
+	// 2. Decrypt and unpad.
+	iv := rawToken[ivOffset:ciphertextOffset]
+	ciphertext := rawToken[ciphertextOffset : len(rawToken)-sha256.Size]
+
+	decrypter, _ := padmode.NewPaddedDecrypter(cipher.NewCBCDecrypter(f.block, iv), f.padder)
+	var result []byte
+	result, err = decrypter.Open(nil, ciphertext)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return result, nil
+}
+
+// ExtractTimestamp verifies a token and returns its timestamp.
+// The token is not decrypted.
+func (f *Fernet) ExtractTimestamp(token []byte) (time.Time, error) {
+	_, timestamp, err := f.verify(token)
+
+	return timestamp, err
+}
+
+// ******** Private functions ********
+
+// encrypt encrypts data into a token with the supplied timestamp and iv.
+func (f *Fernet) encrypt(data []byte, now time.Time, iv []byte) []byte {
+	// 1. Build the header.
+	rawToken := make([]byte, ciphertextOffset, minTokenLen+len(data))
+	rawToken[0] = version
+	binary.BigEndian.PutUint64(rawToken[timestampOffset:ivOffset], uint64(now.Unix()))
+	copy(rawToken[ivOffset:ciphertextOffset], iv)
+
+	// 2. Pad and encrypt.
+	encrypter, _ := padmode.NewPaddedEncrypter(cipher.NewCBCEncrypter(f.block, iv), f.padder)
+	rawToken = encrypter.Seal(rawToken, data)
+
+	// 3. Sign.
+	rawToken = f.sign(rawToken, rawToken)
+
+	// 4. Encode.
+	result := make([]byte, base64.URLEncoding.EncodedLen(len(rawToken)))
+	base64.URLEncoding.Encode(result, rawToken)
+
+	return result
+}
+
+// verify decodes a token and verifies its structure and its HMAC.
+// It returns the decoded token and its timestamp.
+func (f *Fernet) verify(token []byte) ([]byte, time.Time, error) {
+	// 1. Decode.
+	rawToken := make([]byte, base64.URLEncoding.DecodedLen(len(token)))
+	rawTokenLen, err := base64.URLEncoding.Decode(rawToken, token)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidToken
+	}
+
+	rawToken = rawToken[:rawTokenLen]
+
+	// 2. Check the structure.
+	if rawTokenLen < minTokenLen ||
+		(rawTokenLen-minTokenLen)%aes.BlockSize != 0 ||
+		rawToken[0] != version {
+		return nil, time.Time{}, ErrInvalidToken
+	}
+
+	// 3. Check the HMAC in constant time.
+	macOffset := rawTokenLen - sha256.Size
+	expectedMAC := f.sign(make([]byte, 0, sha256.Size), rawToken[:macOffset])
+	if !hmac.Equal(rawToken[macOffset:], expectedMAC) {
+		return nil, time.Time{}, ErrInvalidToken
+	}
+
+	timestamp := int64(binary.BigEndian.Uint64(rawToken[timestampOffset:ivOffset]))
+
+	return rawToken, time.Unix(timestamp, 0), nil
+}
+
+// sign calculates the HMAC of data and appends it to dst.
+func (f *Fernet) sign(dst []byte, data []byte) []byte {
+	mac := hmac.New(sha256.New, f.signingKey)
+	mac.Write(data)
+
+	return mac.Sum(dst)
+}
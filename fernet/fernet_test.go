@@ -0,0 +1,232 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fernet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// ******** Private constants ********
+
+// These are the values of the test vectors of the Fernet specification.
+const (
+	specSecret = `cw_0x689RpI-jtRR7oE8h_eQsKImvJapLeSbXpwF4e4=`
+	specToken  = `gAAAAAAdwJ6wAAECAwQFBgcICQoLDA0ODy021cpGVWKZ_eEwCGM4BLLF_5CV9dOPmrhuVUPgJobwOz7JcbmrR64jVmpU4IwqDA==`
+	specSource = `hello`
+	specTTL    = 60 * time.Second
+)
+
+// ******** Private variables ********
+
+// specIV is the initialization vector of the specification's generate test vector.
+var specIV = []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// ******** Test vectors ********
+
+func TestSpecGenerate(t *testing.T) {
+	f := makeSpecFernet(t)
+
+	now := parseTime(t, `1985-10-26T01:20:00-07:00`)
+	token := f.encrypt([]byte(specSource), now, specIV)
+	if string(token) != specToken {
+		t.Fatalf(`Generated token '%s' differs from specification token '%s'`, token, specToken)
+	}
+}
+
+func TestSpecVerify(t *testing.T) {
+	f := makeSpecFernet(t)
+
+	now := parseTime(t, `1985-10-26T01:20:01-07:00`)
+	data, err := f.DecryptAtTime([]byte(specToken), specTTL, now)
+	if err != nil {
+		t.Fatalf(`Decryption of specification token failed: %v`, err)
+	}
+	if string(data) != specSource {
+		t.Fatalf(`Decrypted data '%s' differs from specification source '%s'`, data, specSource)
+	}
+
+	var timestamp time.Time
+	timestamp, err = f.ExtractTimestamp([]byte(specToken))
+	if err != nil {
+		t.Fatalf(`Extracting timestamp failed: %v`, err)
+	}
+	if !timestamp.Equal(parseTime(t, `1985-10-26T01:20:00-07:00`)) {
+		t.Fatalf(`Wrong timestamp %v`, timestamp)
+	}
+}
+
+// ******** Functional tests ********
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf(`Could not generate key: %v`, err)
+	}
+
+	var f *Fernet
+	f, err = NewFernet(key)
+	if err != nil {
+		t.Fatalf(`Could not create Fernet: %v`, err)
+	}
+
+	for dataLen := 0; dataLen <= 3*aes.BlockSize; dataLen++ {
+		data := bytes.Repeat([]byte{'x'}, dataLen)
+
+		var token []byte
+		token, err = f.Encrypt(data)
+		if err != nil {
+			t.Fatalf(`Encryption failed: %v`, err)
+		}
+
+		var decryptedData []byte
+		decryptedData, err = f.Decrypt(token, time.Minute)
+		if err != nil {
+			t.Fatalf(`Decryption failed (dataLen=%d): %v`, dataLen, err)
+		}
+		if !bytes.Equal(decryptedData, data) {
+			t.Fatalf(`Decrypted data differs from data (dataLen=%d)`, dataLen)
+		}
+	}
+}
+
+// ******** Test invalid tokens ********
+
+func TestInvalidTokens(t *testing.T) {
+	f := makeSpecFernet(t)
+	now := parseTime(t, `1985-10-26T01:20:01-07:00`)
+
+	rawToken, err := base64.URLEncoding.DecodeString(specToken)
+	if err != nil {
+		t.Fatalf(`Could not decode specification token: %v`, err)
+	}
+
+	// Incorrect MAC.
+	incorrectMAC := bytes.Clone(rawToken)
+	incorrectMAC[len(incorrectMAC)-1] ^= 0x01
+
+	// Wrong version.
+	wrongVersion := bytes.Clone(rawToken[:len(rawToken)-sha256.Size])
+	wrongVersion[0] = 0x81
+	wrongVersion = f.sign(wrongVersion, wrongVersion)
+
+	// Payload size not a multiple of the block size.
+	wrongSize := bytes.Clone(rawToken[:len(rawToken)-sha256.Size-1])
+	wrongSize = f.sign(wrongSize, wrongSize)
+
+	// Correct MAC but invalid padding.
+	paddingError := makeRawToken(f, now, []byte(`hello`), 0x55)
+
+	invalidTokens := map[string]string{
+		`incorrect mac`:          base64.URLEncoding.EncodeToString(incorrectMAC),
+		`wrong version`:          base64.URLEncoding.EncodeToString(wrongVersion),
+		`too short`:              base64.URLEncoding.EncodeToString(rawToken[:20]),
+		`invalid base64`:         specToken[:len(specToken)-3] + `%%%`,
+		`payload size`:           base64.URLEncoding.EncodeToString(wrongSize),
+		`payload padding error`:  base64.URLEncoding.EncodeToString(paddingError),
+		`empty`:                  ``,
+		`specification modified`: `hAAAAAAdwJ6wAAECAwQFBgcICQoLDA0ODy021cpGVWKZ_eEwCGM4BLLF_5CV9dOPmrhuVUPgJobwOz7JcbmrR64jVmpU4IwqDA==`,
+	}
+
+	for name, token := range invalidTokens {
+		_, err = f.DecryptAtTime([]byte(token), specTTL, now)
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf(`Wrong error for invalid token '%s': %v`, name, err)
+		}
+	}
+}
+
+func TestTimestampChecks(t *testing.T) {
+	f := makeSpecFernet(t)
+
+	// Expired TTL.
+	_, err := f.DecryptAtTime([]byte(specToken), specTTL, parseTime(t, `1985-10-26T01:21:31-07:00`))
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf(`Wrong error for expired token: %v`, err)
+	}
+
+	// No TTL.
+	_, err = f.DecryptAtTime([]byte(specToken), 0, parseTime(t, `2015-10-21T16:29:00-07:00`))
+	if err != nil {
+		t.Fatalf(`Decryption without TTL failed: %v`, err)
+	}
+
+	// Far-future timestamp (unacceptable clock skew).
+	_, err = f.DecryptAtTime([]byte(specToken), specTTL, parseTime(t, `1985-10-26T01:18:59-07:00`))
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf(`Wrong error for token from the future: %v`, err)
+	}
+}
+
+// ******** Test invalid keys ********
+
+func TestInvalidKeys(t *testing.T) {
+	for _, key := range []string{``, `abc`, specSecret[:len(specSecret)-4], `cw/0x689RpI+jtRR7oE8h/eQsKImvJapLeSbXpwF4e4=`} {
+		_, err := NewFernet(key)
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf(`Wrong error for invalid key '%s': %v`, key, err)
+		}
+	}
+}
+
+// ******** Private functions ********
+
+// makeSpecFernet creates a Fernet with the key of the specification's test vectors.
+func makeSpecFernet(t *testing.T) *Fernet {
+	f, err := NewFernet(specSecret)
+	if err != nil {
+		t.Fatalf(`Could not create Fernet: %v`, err)
+	}
+
+	return f
+}
+
+// makeRawToken creates a correctly signed raw token whose last plaintext block is filled with fillByte.
+func makeRawToken(f *Fernet, now time.Time, data []byte, fillByte byte) []byte {
+	block := bytes.Repeat([]byte{fillByte}, aes.BlockSize)
+	copy(block, data)
+	cipher.NewCBCEncrypter(f.block, specIV).CryptBlocks(block, block)
+
+	rawToken := make([]byte, ciphertextOffset)
+	rawToken[0] = version
+	binary.BigEndian.PutUint64(rawToken[timestampOffset:ivOffset], uint64(now.Unix()))
+	copy(rawToken[ivOffset:], specIV)
+	rawToken = append(rawToken, block...)
+
+	return f.sign(rawToken, rawToken)
+}
+
+// parseTime parses an RFC 3339 time.
+func parseTime(t *testing.T, s string) time.Time {
+	result, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf(`Could not parse time '%s': %v`, s, err)
+	}
+
+	return result
+}
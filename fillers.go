@@ -30,30 +30,30 @@ import (
 
 // zeroFiller creates a filler with all zeroes.
 // This filler panics if the clear data ends with a 0 byte in the last block.
-func zeroFiller(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func zeroFiller(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	if lastBlockDataLen > 0 && lastData[lastBlockDataLen-1] == 0 {
 		panic(`last data byte must not be 0`)
 	}
 }
 
 // pkcs7Filler creates a filler with all bytes containing the length of the filler.
-func pkcs7Filler(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func pkcs7Filler(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	slicehelper.Fill(lastBlock, byte(padLen))
 }
 
 // x923Filler contains a filler where the last byte contains the length and all other bytes are zero.
-func x923Filler(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func x923Filler(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	lastBlock[blockSize-1] = byte(padLen)
 }
 
 // iso10126Filler contains a filler where the last byte contains the length and all other bytes have random values.
-func iso10126Filler(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func iso10126Filler(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	_, _ = rand.Read(lastBlock)
 	lastBlock[blockSize-1] = byte(padLen)
 }
 
 // rfc4303Filler contains a filler where the last byte contains the length and the other bytes are counted down from right to left.
-func rfc4303Filler(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func rfc4303Filler(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	padByte := byte(padLen)
 	for i := blockSize - 1; i >= 0; i-- {
 		lastBlock[i] = padByte
@@ -62,13 +62,13 @@ func rfc4303Filler(lastBlock []byte, blockSize int, lastData []byte, lastBlockDa
 }
 
 // iso78164Filler contains a filler where the first byte contains the value 0x80 and all other bytes are zero.
-func iso78164Filler(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func iso78164Filler(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	lastBlock[lastBlockDataLen] = 0x80
 }
 
 // arbitraryTailByteFiller contains a filler where all bytes contain the same random value which is not the value of the last data byte.
 // This padding is *not* susceptible to a padding oracle!
-func arbitraryTailByteFiller(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func arbitraryTailByteFiller(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	fillByte := getArbitraryTailBytePaddingFillByte(lastData, lastBlockDataLen)
 	slicehelper.Fill(lastBlock, fillByte)
 }
@@ -77,7 +77,7 @@ func arbitraryTailByteFiller(lastBlock []byte, blockSize int, lastData []byte, l
 // It is a simplified version of arbitrary tail byte padding which does not need the expensive creation
 // of a random byte.
 // This padding is *not* susceptible to a padding oracle!
-func notLastBytePaddingFiller(lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int) {
+func notLastBytePaddingFiller(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
 	var fillByte byte
 
 	if lastBlockDataLen > 0 {
@@ -89,6 +89,35 @@ func notLastBytePaddingFiller(lastBlock []byte, blockSize int, lastData []byte,
 	slicehelper.Fill(lastBlock, fillByte)
 }
 
+// mcryptZeroFiller creates a filler for the zero padding of PHP mcrypt.
+// The last block already consists of zero bytes, so there is nothing to do.
+// It is only called if the data is not a multiple of the block size.
+func mcryptZeroFiller(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
+}
+
+// iso97971M3Filler creates a filler for ISO 9797-1 method 3.
+// The last block already consists of zero bytes, so there is nothing to do for it.
+// The prefix block contains the length of the data in bits as a big-endian number.
+// This filler panics if the length in bits does not fit into the prefix block.
+func iso97971M3Filler(prefixBlock []byte, lastBlock []byte, blockSize int, lastData []byte, lastBlockDataLen int, padLen int, dataLen int) {
+	if prefixBlock == nil {
+		return
+	}
+
+	bitLen := uint64(dataLen)
+	overflow := bitLen >> 61
+	bitLen <<= 3
+
+	for i := blockSize - 1; i >= 0; i-- {
+		prefixBlock[i] = byte(bitLen)
+		bitLen >>= 8
+	}
+
+	if bitLen != 0 || overflow != 0 {
+		panic(`data length does not fit into the prefix block`)
+	}
+}
+
 // -------- Helper functions --------
 
 // getArbitraryTailBytePaddingFillByte gets the byte that is used for padding with arbitrary tail byte padding.
@@ -0,0 +1,115 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package http2pad implements the padding of HTTP/2 DATA, HEADERS and PUSH_PROMISE frames
+// as specified in RFC 9113, section 6.1:
+//
+//	Pad Length || data || padding
+//
+// The padding consists of zero bytes. The frame payload is padded to a multiple of a granularity to hide
+// the length of the data. The PADDED flag of the frame has to be set by the caller.
+// For HEADERS frames the priority fields are part of the data.
+package http2pad
+
+import (
+	"errors"
+	"github.com/xformerfhs/blockpad/internal/blocksize"
+)
+
+// ******** Public types ********
+
+// Padder adds and strips the padding of HTTP/2 frames.
+//
+// A Padder is safe for concurrent use by multiple goroutines, as it is used read-only.
+type Padder struct {
+	granularity int
+}
+
+// ******** Private constants ********
+
+// padLengthSize is the size of the Pad Length field.
+const padLengthSize = 1
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidGranularity means that the granularity is not between 1 and 255.
+	ErrInvalidGranularity = errors.New(`invalid granularity`)
+
+	// ErrProtocolError means that the padding of an HTTP/2 frame is malformed.
+	// It corresponds to the HTTP/2 error code PROTOCOL_ERROR.
+	ErrProtocolError = errors.New(`HTTP/2 protocol error`)
+)
+
+// ******** Public creation function ********
+
+// NewPadder creates an HTTP/2 frame padder.
+// The frame payload is padded to a multiple of granularity. A granularity of 1 means no padding.
+// The granularity must not be greater than 255, so the padding always fits into the Pad Length field.
+func NewPadder(granularity int) (*Padder, error) {
+	if !blocksize.IsValid(granularity) {
+		return nil, ErrInvalidGranularity
+	}
+
+	return &Padder{granularity: granularity}, nil
+}
+
+// ******** Public functions ********
+
+// Pad returns a new slice that contains the Pad Length field, the data and the zero padding.
+func (hp *Padder) Pad(data []byte) []byte {
+	unpaddedLen := padLengthSize + len(data)
+	padLen := (hp.granularity - unpaddedLen%hp.granularity) % hp.granularity
+
+	// The padding consists of the zero bytes created by make.
+	result := make([]byte, unpaddedLen+padLen)
+	result[0] = byte(padLen)
+	copy(result[padLengthSize:], data)
+
+	return result
+}
+
+// Unpad strips the Pad Length field and the padding from the payload of a frame with the PADDED flag.
+// It returns a byte slice into the supplied payload and does not allocate a new slice.
+// If the padding is longer than the payload or contains bytes that are not zero, ErrProtocolError is returned.
+func (hp *Padder) Unpad(framePayload []byte) ([]byte, error) {
+	payloadLen := len(framePayload)
+	if payloadLen < padLengthSize {
+		return nil, ErrProtocolError
+	}
+
+	padLen := int(framePayload[0])
+	if padLen > payloadLen-padLengthSize {
+		return nil, ErrProtocolError
+	}
+
+	firstPadIndex := payloadLen - padLen
+
+	var nonZero byte
+	for _, b := range framePayload[firstPadIndex:] {
+		nonZero |= b
+	}
+
+	if nonZero != 0 {
+		return nil, ErrProtocolError
+	}
+
+	return framePayload[padLengthSize:firstPadIndex], nil
+}
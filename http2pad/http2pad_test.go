@@ -0,0 +1,110 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http2pad
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the maximum data length in the functional tests.
+const loopCount = 100
+
+// ******** Functional tests ********
+
+func TestPadVector(t *testing.T) {
+	padder, err := NewPadder(8)
+	if err != nil {
+		t.Fatalf(`Error creating HTTP/2 padder: %v`, err)
+	}
+
+	// 1 byte Pad Length, 3 bytes data and 4 bytes padding.
+	padded := padder.Pad([]byte(`abc`))
+	if hex.EncodeToString(padded) != `0461626300000000` {
+		t.Fatalf(`Wrong padded frame payload: %x`, padded)
+	}
+}
+
+func TestPadUnpad(t *testing.T) {
+	for _, granularity := range []int{1, 16, 64, 255} {
+		padder, err := NewPadder(granularity)
+		if err != nil {
+			t.Fatalf(`Error creating HTTP/2 padder with granularity %d: %v`, granularity, err)
+		}
+
+		for dataLen := 0; dataLen <= loopCount; dataLen++ {
+			data := testhelper.MakeTestSlice(dataLen)
+
+			padded := padder.Pad(data)
+			if len(padded)%granularity != 0 {
+				t.Fatalf(`Padded length %d is not a multiple of %d`, len(padded), granularity)
+			}
+
+			var unpadded []byte
+			unpadded, err = padder.Unpad(padded)
+			if err != nil {
+				t.Fatalf(`Unpad with data length %d failed: %v`, dataLen, err)
+			}
+			if !bytes.Equal(unpadded, data) {
+				t.Fatalf(`Unpadded data differ from data with length %d`, dataLen)
+			}
+		}
+	}
+}
+
+// ******** Error tests ********
+
+func TestInvalidPadding(t *testing.T) {
+	padder, _ := NewPadder(16)
+
+	for _, framePayload := range [][]byte{
+		nil,
+		{1},
+		{4, 'a', 0, 0},
+		{2, 'a', 0, 1},
+		{255, 'a'},
+	} {
+		_, err := padder.Unpad(framePayload)
+		if !errors.Is(err, ErrProtocolError) {
+			t.Fatalf(`Malformed frame payload %02x was not detected: %v`, framePayload, err)
+		}
+	}
+
+	// Padding that spans the whole frame payload is valid.
+	unpadded, err := padder.Unpad([]byte{3, 0, 0, 0})
+	if err != nil || len(unpadded) != 0 {
+		t.Fatalf(`Frame payload with only padding was not accepted: %v`, err)
+	}
+}
+
+func TestInvalidGranularity(t *testing.T) {
+	for _, granularity := range []int{0, 256} {
+		_, err := NewPadder(granularity)
+		if !errors.Is(err, ErrInvalidGranularity) {
+			t.Fatalf(`Invalid granularity %d was not detected: %v`, granularity, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: Frank Schwab
+//
+
+// Package blocksize implements the validation of block sizes.
+package blocksize
+
+import "math"
+
+// ******** Public constants ********
+
+// MaxBlockSize is the maximum block size.
+// The padding length of a full block has to fit into one byte.
+const MaxBlockSize = math.MaxUint8
+
+// ******** Public functions ********
+
+// IsValid checks if the block size is between 1 and MaxBlockSize.
+func IsValid(blockSize int) bool {
+	return IsValidUpTo(blockSize, MaxBlockSize)
+}
+
+// IsValidUpTo checks if the block size is between 1 and maxBlockSize.
+// It is used for protocols where the length of a padding does not have to fit into one byte.
+func IsValidUpTo(blockSize int, maxBlockSize int) bool {
+	return blockSize >= 1 && blockSize <= maxBlockSize
+}
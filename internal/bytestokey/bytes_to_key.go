@@ -0,0 +1,49 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: Frank Schwab
+//
+
+// Package bytestokey implements the OpenSSL key derivation function EVP_BytesToKey.
+package bytestokey
+
+import "hash"
+
+// ******** Public functions ********
+
+// Key implements the OpenSSL function EVP_BytesToKey with an iteration count of 1.
+// D_i = HASH(D_(i-1) || password || salt), and the result is D_1 || D_2 || ... truncated to keyLen bytes.
+func Key(hashFunc func() hash.Hash, password []byte, salt []byte, keyLen int) []byte {
+	h := hashFunc()
+	result := make([]byte, 0, keyLen+h.Size())
+
+	var digest []byte
+	for len(result) < keyLen {
+		h.Reset()
+		h.Write(digest)
+		h.Write(password)
+		h.Write(salt)
+		digest = h.Sum(nil)
+
+		result = append(result, digest...)
+	}
+
+	return result[:keyLen]
+}
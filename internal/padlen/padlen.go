@@ -0,0 +1,39 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: Frank Schwab
+//
+
+// Package padlen implements the calculation of padding lengths.
+package padlen
+
+// ******** Public functions ********
+
+// Lengths calculates the 3 lengths needed for padding.
+// It returns the length of full data blocks, the length of the last data block
+// and the length of the padding needed.
+func Lengths(dataLen int, blockSize int) (int, int, int) {
+	fullBlockCount := dataLen / blockSize
+	fullBlockDataLen := fullBlockCount * blockSize
+	lastBlockDataLen := dataLen - fullBlockDataLen
+	padLen := blockSize - lastBlockDataLen
+
+	return fullBlockDataLen, lastBlockDataLen, padLen
+}
@@ -0,0 +1,74 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: Frank Schwab
+//
+
+// Package pbkdf2 implements the PBKDF2 key derivation function of RFC 8018.
+//
+// This is a copy of the algorithm in golang.org/x/crypto/pbkdf2 to get rid of
+// the golang.org/x/crypto dependency. The crypto/pbkdf2 package is only available
+// since Go 1.24.
+package pbkdf2
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// ******** Public functions ********
+
+// Key derives a key of length keyLen from the password, the salt and the iteration count
+// with the HMAC of the supplied hash function as pseudorandom function.
+func Key(password []byte, salt []byte, iterations int, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	blockCount := (keyLen + hashLen - 1) / hashLen
+
+	var counter [4]byte
+	result := make([]byte, 0, blockCount*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= blockCount; block++ {
+		// 1. U_1 = PRF(password, salt || INT(block)).
+		prf.Reset()
+		prf.Write(salt)
+		counter[0] = byte(block >> 24)
+		counter[1] = byte(block >> 16)
+		counter[2] = byte(block >> 8)
+		counter[3] = byte(block)
+		prf.Write(counter[:])
+		result = prf.Sum(result)
+		t := result[len(result)-hashLen:]
+		copy(u, t)
+
+		// 2. T = U_1 ^ U_2 ^ ... ^ U_iterations.
+		for n := 2; n <= iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = u[:0]
+			u = prf.Sum(u)
+			for i, b := range u {
+				t[i] ^= b
+			}
+		}
+	}
+
+	return result[:keyLen]
+}
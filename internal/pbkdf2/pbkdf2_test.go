@@ -0,0 +1,66 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: Frank Schwab
+//
+
+package pbkdf2
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+// testVector holds a PBKDF2 test vector.
+type testVector struct {
+	h          func() hash.Hash
+	password   string
+	salt       string
+	iterations int
+	key        string
+}
+
+// testVectors contains the test vectors of RFC 6070 and of RFC 7914, section 11.
+var testVectors = []testVector{
+	{sha1.New, `password`, `salt`, 1, `0c60c80f961f0e71f3a9b524af6012062fe037a6`},
+	{sha1.New, `password`, `salt`, 2, `ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957`},
+	{sha1.New, `password`, `salt`, 4096, `4b007901b765489abead49d926f721d065a429c1`},
+	{sha1.New, `passwordPASSWORDpassword`, `saltSALTsaltSALTsaltSALTsaltSALTsalt`, 4096, `3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038`},
+	{sha1.New, "pass\x00word", "sa\x00lt", 4096, `56fa6aa75548099dcc37d7f03425e0c3`},
+	{sha256.New, `passwd`, `salt`, 1, `55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783`},
+}
+
+func TestKey(t *testing.T) {
+	for _, tv := range testVectors {
+		expected, err := hex.DecodeString(tv.key)
+		if err != nil {
+			t.Fatalf(`Could not decode key '%s': %v`, tv.key, err)
+		}
+
+		key := Key([]byte(tv.password), []byte(tv.salt), tv.iterations, len(expected), tv.h)
+		if !bytes.Equal(key, expected) {
+			t.Fatalf(`Wrong key for password '%s' and %d iterations: %02x`, tv.password, tv.iterations, key)
+		}
+	}
+}
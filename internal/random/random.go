@@ -0,0 +1,37 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: Frank Schwab
+//
+
+// Package random implements the filling of byte slices with cryptographically secure random bytes.
+package random
+
+import "crypto/rand"
+
+// ******** Public functions ********
+
+// Fill fills a byte slice with cryptographically secure random bytes.
+// It returns an error, if the random number generator fails.
+func Fill(b []byte) error {
+	_, err := rand.Read(b)
+
+	return err
+}
@@ -80,6 +80,24 @@ func Concat[S ~[]T, T any](slices ...S) S {
 	return result
 }
 
+// ForAppend extends a slice by n elements.
+// It returns the extended slice and a slice of the n appended elements.
+// If the capacity of the slice is sufficient, no new slice is allocated.
+// This is the same as the sliceForAppend function in the Go crypto packages.
+func ForAppend[S ~[]T, T any](s S, n int) (S, S) {
+	total := len(s) + n
+
+	var head S
+	if cap(s) >= total {
+		head = s[:total]
+	} else {
+		head = make(S, total)
+		copy(head, s)
+	}
+
+	return head, head[len(s):]
+}
+
 // ******** Private functions ********
 
 // doSimpleFill fills a slice in a simple way.
@@ -78,3 +78,29 @@ func TestConcat(t *testing.T) {
 		}
 	}
 }
+
+func TestForAppend(t *testing.T) {
+	a := make([]byte, 3, 10)
+	Fill(a, 7)
+
+	head, tail := ForAppend(a, 5)
+	if len(head) != 8 || len(tail) != 5 {
+		t.Fatal(`Wrong lengths of slices for append.`)
+	}
+	if &head[0] != &a[0] {
+		t.Fatal(`Slice for append was allocated although capacity was sufficient.`)
+	}
+
+	head, tail = ForAppend(a, 11)
+	if len(head) != 14 || len(tail) != 11 {
+		t.Fatal(`Wrong lengths of extended slices for append.`)
+	}
+	if &head[0] == &a[0] {
+		t.Fatal(`Slice for append was not allocated although capacity was not sufficient.`)
+	}
+	for _, e := range head[:3] {
+		if e != 7 {
+			t.Fatal(`Error copying data into new slice for append.`)
+		}
+	}
+}
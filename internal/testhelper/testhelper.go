@@ -0,0 +1,48 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: Frank Schwab
+//
+
+// Package testhelper implements helper functions that are shared by the tests of the subpackages.
+package testhelper
+
+import "crypto/rand"
+
+// ******** Public functions ********
+
+// MakeTestSlice creates a test slice of a given length with random content.
+func MakeTestSlice(len int) []byte {
+	data := make([]byte, len)
+	_, _ = rand.Read(data)
+
+	return data
+}
+
+// MakeZeroSafeTestSlice creates a test slice of a given length with random content
+// that does not end with a 0 byte, so that it can be used with Zero padding.
+func MakeZeroSafeTestSlice(len int) []byte {
+	data := MakeTestSlice(len)
+	if len > 0 && data[len-1] == 0 {
+		data[len-1] = 0xff
+	}
+
+	return data
+}
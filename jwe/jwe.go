@@ -0,0 +1,239 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jwe implements the AES-CBC-HMAC-SHA2 content encryption algorithms
+// of JSON Web Encryption as specified in RFC 7518, section 5.2.
+//
+// These are A128CBC-HS256, A192CBC-HS384 and A256CBC-HS512.
+// The content encryption is exposed as a [crypto/cipher/AEAD] where the nonce is the
+// JWE initialization vector and the sealed data consists of the JWE ciphertext followed by the JWE authentication tag.
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/padmode"
+	"hash"
+)
+
+// ******** Public types ********
+
+// Algorithm is the type that holds content encryption algorithms.
+type Algorithm byte
+
+// ******** Public constants ********
+
+// These are the valid content encryption algorithms.
+const (
+	// A128CBCHS256 is AES-128-CBC with HMAC-SHA256 truncated to 16 bytes.
+	A128CBCHS256 Algorithm = iota
+
+	// A192CBCHS384 is AES-192-CBC with HMAC-SHA384 truncated to 24 bytes.
+	A192CBCHS384
+
+	// A256CBCHS512 is AES-256-CBC with HMAC-SHA512 truncated to 32 bytes.
+	A256CBCHS512
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidAlgorithm means that the content encryption algorithm is invalid.
+	ErrInvalidAlgorithm = errors.New(`invalid content encryption algorithm`)
+
+	// ErrInvalidKeySize means that the key does not have the size required by the algorithm.
+	ErrInvalidKeySize = errors.New(`invalid key size`)
+
+	// ErrAuthenticationFailed means that the message could not be authenticated.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrAuthenticationFailed = errors.New(`message authentication failed`)
+)
+
+// ******** Private types ********
+
+// algorithmInfo holds the parameters of a content encryption algorithm.
+type algorithmInfo struct {
+	name     string
+	keySize  int
+	hashFunc func() hash.Hash
+}
+
+// aesCBCHMAC holds the data necessary for an AES-CBC-HMAC-SHA2 content encryption.
+type aesCBCHMAC struct {
+	block   cipher.Block
+	padder  *blockpad.BlockPad
+	info    *algorithmInfo
+	macKey  []byte
+	tagSize int
+}
+
+// ******** Private constants ********
+
+// algorithmImplementation holds the parameters of the content encryption algorithms.
+var algorithmImplementation = []algorithmInfo{
+	{name: `A128CBC-HS256`, keySize: 32, hashFunc: sha256.New},
+	{name: `A192CBC-HS384`, keySize: 48, hashFunc: sha512.New384},
+	{name: `A256CBC-HS512`, keySize: 64, hashFunc: sha512.New},
+}
+
+// ******** Public creation function ********
+
+// NewAEAD creates a content encryption with the supplied algorithm and key.
+// The first half of the key is the MAC key, the second half is the encryption key.
+func NewAEAD(algorithm Algorithm, key []byte) (cipher.AEAD, error) {
+	if int(algorithm) >= len(algorithmImplementation) {
+		return nil, ErrInvalidAlgorithm
+	}
+
+	info := &algorithmImplementation[algorithm]
+	if len(key) != info.keySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	halfKeySize := info.keySize >> 1
+	block, err := aes.NewCipher(key[halfKeySize:])
+	if err != nil {
+		return nil, err
+	}
+
+	var padder *blockpad.BlockPad
+	padder, err = blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesCBCHMAC{
+		block:   block,
+		padder:  padder,
+		info:    info,
+		macKey:  append([]byte(nil), key[:halfKeySize]...),
+		tagSize: halfKeySize,
+	}, nil
+}
+
+// ******** Public functions ********
+
+// KeySize returns the key size of the algorithm.
+func (a Algorithm) KeySize() int {
+	if int(a) >= len(algorithmImplementation) {
+		return 0
+	}
+
+	return algorithmImplementation[a].keySize
+}
+
+// String yields the JWE name of the algorithm.
+// It implements the Stringer interface.
+func (a Algorithm) String() string {
+	if int(a) >= len(algorithmImplementation) {
+		return `invalid`
+	}
+
+	return algorithmImplementation[a].name
+}
+
+// NonceSize returns the size of the JWE initialization vector.
+func (c *aesCBCHMAC) NonceSize() int {
+	return aes.BlockSize
+}
+
+// Overhead returns the maximum difference between the lengths of a plaintext and its ciphertext.
+// This is the length of a full padding block and the tag.
+func (c *aesCBCHMAC) Overhead() int {
+	return aes.BlockSize + c.tagSize
+}
+
+// Seal pads and encrypts plaintext with the initialization vector in nonce,
+// authenticates the result and the additional data and appends the
+// ciphertext and the authentication tag to dst, returning the updated slice.
+// The additional data is the ASCII representation of the encoded JWE protected header.
+// dst must not overlap plaintext.
+func (c *aesCBCHMAC) Seal(dst []byte, nonce []byte, plaintext []byte, additionalData []byte) []byte {
+	if len(nonce) != aes.BlockSize {
+		panic(`jwe: invalid nonce length`)
+	}
+
+	dstLen := len(dst)
+
+	// 1. Pad and encrypt.
+	encrypter, _ := padmode.NewPaddedEncrypter(cipher.NewCBCEncrypter(c.block, nonce), c.padder)
+	result := encrypter.Seal(dst, plaintext)
+
+	// 2. Authenticate.
+	return append(result, c.calculateTag(additionalData, nonce, result[dstLen:])...)
+}
+
+// Open authenticates the ciphertext, the initialization vector in nonce and the additional data,
+// and, if successful, decrypts and unpads the ciphertext and appends the result to dst,
+// returning the updated slice.
+// The tag is verified in constant time before the padding is removed.
+// Any error is reported as ErrAuthenticationFailed.
+// dst must not overlap ciphertext.
+func (c *aesCBCHMAC) Open(dst []byte, nonce []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(nonce) != aes.BlockSize {
+		panic(`jwe: invalid nonce length`)
+	}
+
+	// 1. Check the length. There must be at least one block and a tag.
+	encryptedLen := len(ciphertext) - c.tagSize
+	if encryptedLen < aes.BlockSize || encryptedLen%aes.BlockSize != 0 {
+		return nil, ErrAuthenticationFailed
+	}
+
+	encrypted := ciphertext[:encryptedLen]
+
+	// 2. Verify the tag before anything else is done with the ciphertext.
+	if !hmac.Equal(ciphertext[encryptedLen:], c.calculateTag(additionalData, nonce, encrypted)) {
+		return nil, ErrAuthenticationFailed
+	}
+
+	// 3. Decrypt and unpad.
+	decrypter, _ := padmode.NewPaddedDecrypter(cipher.NewCBCDecrypter(c.block, nonce), c.padder)
+	result, err := decrypter.Open(dst, encrypted)
+	if err != nil {
+		return nil, ErrAuthenticationFailed
+	}
+
+	return result, nil
+}
+
+// ******** Private functions ********
+
+// calculateTag calculates the truncated HMAC over the additional data, the initialization vector,
+// the ciphertext and the length of the additional data in bits.
+func (c *aesCBCHMAC) calculateTag(additionalData []byte, iv []byte, encrypted []byte) []byte {
+	mac := hmac.New(c.info.hashFunc, c.macKey)
+	mac.Write(additionalData)
+	mac.Write(iv)
+	mac.Write(encrypted)
+
+	var additionalDataBitLen [8]byte
+	binary.BigEndian.PutUint64(additionalDataBitLen[:], uint64(len(additionalData))<<3)
+	mac.Write(additionalDataBitLen[:])
+
+	return mac.Sum(nil)[:c.tagSize]
+}
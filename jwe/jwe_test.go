@@ -0,0 +1,188 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwe
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ******** Private types ********
+
+// testVector holds a test vector of RFC 7518, appendix B.
+type testVector struct {
+	algorithm Algorithm
+	key       string
+	e         string
+	t         string
+}
+
+// ******** Private constants ********
+
+// These are the common values of the test vectors of RFC 7518, appendix B.
+const (
+	rfcPlaintext = `41206369706865722073797374656d206d757374206e6f7420626520726571756972656420746f206265207365637265742c20616e64206974206d7573742062652061626c6520746f2066616c6c20696e746f207468652068616e6473206f662074686520656e656d7920776974686f757420696e636f6e76656e69656e6365`
+	rfcIV        = `1af38c2dc2b96ffdd86694092341bc04`
+	rfcAAD       = `546865207365636f6e64207072696e6369706c65206f66204175677573746520` +
+		`4b6572636b686f666673`
+)
+
+// ******** Private variables ********
+
+// rfcTestVectors are the test vectors of RFC 7518, appendix B.
+var rfcTestVectors = []testVector{
+	{
+		algorithm: A128CBCHS256,
+		key:       `000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f`,
+		e: `c80edfa32ddf39d5ef00c0b468834279a2e46a1b8049f792f76bfe54b903a9c9` +
+			`a94ac9b47ad2655c5f10f9aef71427e2fc6f9b3f399a221489f16362c7032336` +
+			`09d45ac69864e3321cf82935ac4096c86e133314c54019e8ca7980dfa4b9cf1b` +
+			`384c486f3a54c51078158ee5d79de59fbd34d848b3d69550a67646344427ade5` +
+			`4b8851ffb598f7f80074b9473c82e2db`,
+		t: `652c3fa36b0a7c5b3219fab3a30bc1c4`,
+	},
+	{
+		algorithm: A192CBCHS384,
+		key: `000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f` +
+			`202122232425262728292a2b2c2d2e2f`,
+		e: `ea65da6b59e61edb419be62d19712ae5d303eeb50052d0dfd6697f77224c8edb` +
+			`000d279bdc14c1072654bd30944230c657bed4ca0c9f4a8466f22b226d174621` +
+			`4bf8cfc2400add9f5126e479663fc90b3bed787a2f0ffcbf3904be2a641d5c21` +
+			`05bfe591bae23b1d7449e532eef60a9ac8bb6c6b01d35d49787bcd57ef484927` +
+			`f280adc91ac0c4e79c7b11efc60054e3`,
+		t: `8490ac0e58949bfe51875d733f93ac2075168039ccc733d7`,
+	},
+	{
+		algorithm: A256CBCHS512,
+		key: `000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f` +
+			`202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f`,
+		e: `4affaaadb78c31c5da4b1b590d10ffbd3dd8d5d302423526912da037ecbcc7bd` +
+			`822c301dd67c373bccb584ad3e9279c2e6d12a1374b77f077553df829410446b` +
+			`36ebd97066296ae6427ea75c2e0846a11a09ccf5370dc80bfecbad28c73f09b3` +
+			`a3b75e662a2594410ae496b2e2e6609e31e6e02cc837f053d21f37ff4f51950b` +
+			`be2638d09dd7a4930930806d0703b1f6`,
+		t: `4dd3b4c088a7f45c216839645b2012bf2e6269a8c56a816dbc1b267761955bc5`,
+	},
+}
+
+// ******** Test vectors ********
+
+func TestRFCVectors(t *testing.T) {
+	plaintext := decodeHex(t, rfcPlaintext)
+	iv := decodeHex(t, rfcIV)
+	aad := decodeHex(t, rfcAAD)
+
+	for _, tv := range rfcTestVectors {
+		aead := makeAEAD(t, tv.algorithm, decodeHex(t, tv.key))
+		expected := decodeHex(t, tv.e+tv.t)
+
+		sealed := aead.Seal(nil, iv, plaintext, aad)
+		if !bytes.Equal(sealed, expected) {
+			t.Fatalf("%s: sealed data differs from test vector:\n  sealed=%02x\nexpected=%02x", tv.algorithm, sealed, expected)
+		}
+
+		opened, err := aead.Open(nil, iv, sealed, aad)
+		if err != nil {
+			t.Fatalf(`%s: Open failed: %v`, tv.algorithm, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf(`%s: opened data differs from plaintext`, tv.algorithm)
+		}
+	}
+}
+
+// ******** Test manipulated data ********
+
+func TestTamperedData(t *testing.T) {
+	iv := decodeHex(t, rfcIV)
+	aad := decodeHex(t, rfcAAD)
+
+	for _, tv := range rfcTestVectors {
+		aead := makeAEAD(t, tv.algorithm, decodeHex(t, tv.key))
+		sealed := decodeHex(t, tv.e+tv.t)
+
+		for i := range sealed {
+			sealed[i] ^= 0x80
+
+			_, err := aead.Open(nil, iv, sealed, aad)
+			if !errors.Is(err, ErrAuthenticationFailed) {
+				t.Fatalf(`%s: wrong error opening data tampered at index %d: %v`, tv.algorithm, i, err)
+			}
+
+			sealed[i] ^= 0x80
+		}
+
+		_, err := aead.Open(nil, iv, sealed[:len(sealed)-1], aad)
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf(`%s: wrong error opening truncated data: %v`, tv.algorithm, err)
+		}
+
+		_, err = aead.Open(nil, iv, sealed, aad[1:])
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf(`%s: wrong error opening data with wrong additional data: %v`, tv.algorithm, err)
+		}
+	}
+}
+
+// ******** Test invalid parameters ********
+
+func TestInvalidParameters(t *testing.T) {
+	_, err := NewAEAD(A256CBCHS512, make([]byte, 32))
+	if !errors.Is(err, ErrInvalidKeySize) {
+		t.Fatalf(`Wrong error creating AEAD with wrong key size: %v`, err)
+	}
+
+	invalidAlgorithm := A256CBCHS512 + 1
+	_, err = NewAEAD(invalidAlgorithm, make([]byte, 32))
+	if !errors.Is(err, ErrInvalidAlgorithm) {
+		t.Fatalf(`Wrong error creating AEAD with invalid algorithm: %v`, err)
+	}
+
+	if invalidAlgorithm.KeySize() != 0 || !strings.Contains(invalidAlgorithm.String(), `invalid`) {
+		t.Fatal(`Invalid algorithm has a key size or a name`)
+	}
+}
+
+// ******** Private functions ********
+
+// makeAEAD creates a content encryption.
+func makeAEAD(t *testing.T, algorithm Algorithm, key []byte) cipher.AEAD {
+	aead, err := NewAEAD(algorithm, key)
+	if err != nil {
+		t.Fatalf(`Could not create %s: %v`, algorithm, err)
+	}
+
+	return aead
+}
+
+// decodeHex decodes a hex string.
+func decodeHex(t *testing.T, s string) []byte {
+	result, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf(`Could not decode hex string '%s': %v`, s, err)
+	}
+
+	return result
+}
@@ -21,6 +21,7 @@
 package blockpad
 
 import (
+	"github.com/xformerfhs/blockpad/internal/padlen"
 	"github.com/xformerfhs/blockpad/internal/slicehelper"
 )
 
@@ -53,7 +54,31 @@ func NewBlockPadding(padAlgorithm PadAlgorithm, blockSize int) (*BlockPad, error
 func (pb *BlockPad) Pad(data []byte) []byte {
 	fullBlockData, lastBlock := pb.PadLastBlock(data)
 
-	return slicehelper.Concat(fullBlockData, lastBlock)
+	return slicehelper.Concat(pb.PrefixBlock(len(data)), fullBlockData, lastBlock)
+}
+
+// PrefixBlock returns a new slice containing the prefix block for data of length dataLen.
+// The prefix block has to be placed before the data returned by PadLastBlock.
+// It is empty, if the pad algorithm does not prepend a prefix block.
+func (pb *BlockPad) PrefixBlock(dataLen int) []byte {
+	if !pb.worker.hasPrefix {
+		return []byte{}
+	}
+
+	prefixBlock := make([]byte, pb.blockSize)
+	pb.worker.filler(prefixBlock, nil, pb.blockSize, nil, 0, 0, dataLen)
+
+	return prefixBlock
+}
+
+// PrefixLen returns the length of the prefix block.
+// This is the block size, if the pad algorithm prepends a prefix block, and 0 otherwise.
+func (pb *BlockPad) PrefixLen() int {
+	if !pb.worker.hasPrefix {
+		return 0
+	}
+
+	return pb.blockSize
 }
 
 // PadLastBlock pads a byte slice.
@@ -61,12 +86,19 @@ func (pb *BlockPad) Pad(data []byte) []byte {
 // and a new slice containing the last block with padding.
 // Only the last data that does not fit into a full block is copied.
 // This is much more efficient than Pad.
+// If the pad algorithm does not pad data that is a multiple of the block size,
+// the last block is empty for such data.
+// If the pad algorithm prepends a prefix block, it is not included and can be obtained with PrefixBlock.
 func (pb *BlockPad) PadLastBlock(data []byte) ([]byte, []byte) {
 	// 1. Get all kind of lengths.
 	dataLen := len(data)
 	blockSize := pb.blockSize
 
-	fullBlockDataLen, lastBlockDataLen, padLen := padLengths(dataLen, blockSize)
+	fullBlockDataLen, lastBlockDataLen, padLen := padlen.Lengths(dataLen, blockSize)
+	if lastBlockDataLen == 0 && pb.worker.isOptional {
+		return data, []byte{}
+	}
+
 	lastBlock := make([]byte, blockSize)
 	lastData := data[fullBlockDataLen:]
 
@@ -77,7 +109,7 @@ func (pb *BlockPad) PadLastBlock(data []byte) ([]byte, []byte) {
 	copy(lastBlock, pb.zeroBlock[:padLen]) // This copies padLen bytes.
 
 	// 3. Build a full block of filler bytes to help achieve constant-time processing.
-	pb.worker.filler(lastBlock, blockSize, lastData, lastBlockDataLen, padLen)
+	pb.worker.filler(nil, lastBlock, blockSize, lastData, lastBlockDataLen, padLen, dataLen)
 
 	// 4. Finally, copy last data to last block.
 	copy(lastBlock, lastData[:lastBlockDataLen]) // This copies lastBlockDataLen bytes. lastBlockDataLen + padLen = blockSize.
@@ -88,13 +120,31 @@ func (pb *BlockPad) PadLastBlock(data []byte) ([]byte, []byte) {
 // Unpad removes the padding from a byte slice.
 // It returns a byte slice into the supplied data and does not allocate a new slice.
 // If a last block is unpadded it returns a zero-length slice if that last block contains only padding.
+// If the pad algorithm prepends a prefix block, the complete padded data including the prefix block must be supplied.
 func (pb *BlockPad) Unpad(data []byte) ([]byte, error) {
 	dataLen := len(data)
 	if dataLen%pb.blockSize != 0 {
 		return nil, ErrInvalidPaddedDataLen
 	}
 
-	return pb.worker.remover(data, dataLen, pb.blockSize)
+	// Padded data always consists of at least one full block, unless the padding is optional and has no prefix block.
+	if dataLen == 0 {
+		if pb.worker.isOptional && !pb.worker.hasPrefix {
+			return data, nil
+		}
+
+		return nil, ErrInvalidPaddedDataLen
+	}
+
+	prefixLen := pb.PrefixLen()
+	paddedDataLen := dataLen - prefixLen
+
+	return pb.worker.remover(data[:prefixLen], data[prefixLen:], paddedDataLen, paddedDataLen, pb.blockSize)
+}
+
+// BlockSize returns the block size of the padder.
+func (pb *BlockPad) BlockSize() int {
+	return pb.blockSize
 }
 
 // String yields the name of the padding algorithm.
@@ -102,17 +152,3 @@ func (pb *BlockPad) Unpad(data []byte) ([]byte, error) {
 func (pb *BlockPad) String() string {
 	return pb.worker.name
 }
-
-// ******** Private functions ********
-
-// padLengths calculates the 3 lengths needed for padding.
-// It returns the length of full data blocks, the length of the last data block
-// and the length of the padding needed.
-func padLengths(dataLen int, blockSize int) (int, int, int) {
-	fullBlockCount := dataLen / blockSize
-	fullBlockDataLen := fullBlockCount * blockSize
-	lastBlockDataLen := dataLen - fullBlockDataLen
-	padLen := blockSize - lastBlockDataLen
-
-	return fullBlockDataLen, lastBlockDataLen, padLen
-}
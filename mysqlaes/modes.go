@@ -0,0 +1,110 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlaes
+
+import "crypto/cipher"
+
+// ******** This file contains the CFB modes with small segments that are not part of the Go standard library ********
+
+// ******** Private types ********
+
+// cfbS implements the CFB mode with a segment size of 1 or 8 bits.
+type cfbS struct {
+	block       cipher.Block
+	register    []byte
+	output      []byte
+	segmentBits int
+	isDecrypter bool
+}
+
+// ******** CFB mode with small segments ********
+
+// newCFBS creates a CFB stream with a segment size of 1 or 8 bits.
+func newCFBS(block cipher.Block, iv []byte, segmentBits int, isDecrypter bool) cipher.Stream {
+	blockSize := block.BlockSize()
+
+	register := make([]byte, blockSize)
+	copy(register, iv)
+
+	return &cfbS{
+		block:       block,
+		register:    register,
+		output:      make([]byte, blockSize),
+		segmentBits: segmentBits,
+		isDecrypter: isDecrypter,
+	}
+}
+
+// XORKeyStream encrypts or decrypts src into dst.
+// For every segment the register is encrypted, the segment is XORed with the
+// leftmost bits of the output and the ciphertext segment is shifted into the register.
+func (c *cfbS) XORKeyStream(dst []byte, src []byte) {
+	if len(dst) < len(src) {
+		panic(`mysqlaes: output smaller than input`)
+	}
+
+	for i, inByte := range src {
+		if c.segmentBits == 8 {
+			c.block.Encrypt(c.output, c.register)
+			outByte := inByte ^ c.output[0]
+			c.shiftIn(c.cipherSegment(inByte, outByte), 8)
+			dst[i] = outByte
+			continue
+		}
+
+		var outByte byte
+		for bit := 7; bit >= 0; bit-- {
+			c.block.Encrypt(c.output, c.register)
+			inBit := (inByte >> bit) & 1
+			outBit := inBit ^ (c.output[0] >> 7)
+			c.shiftIn(c.cipherSegment(inBit, outBit), 1)
+			outByte |= outBit << bit
+		}
+
+		dst[i] = outByte
+	}
+}
+
+// cipherSegment returns the ciphertext segment, which is the input when decrypting and the output when encrypting.
+func (c *cfbS) cipherSegment(in byte, out byte) byte {
+	if c.isDecrypter {
+		return in
+	}
+
+	return out
+}
+
+// shiftIn shifts the register left by segmentBits bits and puts the segment into the rightmost bits.
+func (c *cfbS) shiftIn(segment byte, segmentBits int) {
+	lastIndex := len(c.register) - 1
+
+	if segmentBits == 8 {
+		copy(c.register, c.register[1:])
+		c.register[lastIndex] = segment
+		return
+	}
+
+	for i := 0; i < lastIndex; i++ {
+		c.register[i] = c.register[i]<<1 | c.register[i+1]>>7
+	}
+
+	c.register[lastIndex] = c.register[lastIndex]<<1 | segment
+}
@@ -0,0 +1,256 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mysqlaes implements functions that are compatible with the MySQL functions AES_ENCRYPT and AES_DECRYPT.
+//
+// MySQL folds the key string into a key of the size required by the block encryption mode
+// by XORing all bytes of the key string into a zero-initialized key buffer.
+// The modes ECB and CBC use PKCS#7 padding. The modes CFB1, CFB8, CFB128 and OFB do not need padding.
+// All modes except ECB need an initialization vector of at least 16 bytes. Only the first 16 bytes are used.
+//
+// ATTENTION: The key folding is not a key derivation function and ECB mode leaks patterns in the data.
+// These functions should only be used to migrate data out of MySQL.
+package mysqlaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/blockmode"
+	"github.com/xformerfhs/blockpad/padmode"
+	"strings"
+)
+
+// ******** Public types ********
+
+// Crypter encrypts and decrypts data like AES_ENCRYPT and AES_DECRYPT with one block encryption mode.
+//
+// A Crypter is safe for concurrent use by multiple goroutines, as it is used read-only.
+type Crypter struct {
+	info   *modeInfo
+	padder *blockpad.BlockPad
+}
+
+// ******** Public constants ********
+
+// DefaultBlockEncryptionMode is the default value of the MySQL system variable block_encryption_mode.
+const DefaultBlockEncryptionMode = `aes-128-ecb`
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidBlockEncryptionMode means that the block encryption mode is not known.
+	ErrInvalidBlockEncryptionMode = errors.New(`invalid block encryption mode`)
+
+	// ErrDecryptionFailed means that the data could not be decrypted.
+	// This is the case where MySQL returns NULL.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrDecryptionFailed = errors.New(`decryption failed`)
+)
+
+// ******** Private types ********
+
+// chainMode is the type that holds the chaining modes.
+type chainMode byte
+
+// modeInfo holds the parameters of a block encryption mode.
+type modeInfo struct {
+	keySize int
+	chain   chainMode
+}
+
+// ******** Private constants ********
+
+// These are the chaining modes.
+const (
+	chainECB chainMode = iota
+	chainCBC
+	chainCFB1
+	chainCFB8
+	chainCFB128
+	chainOFB
+)
+
+// chainNames maps the MySQL names of the chaining modes to the chaining modes.
+var chainNames = map[string]chainMode{
+	`ecb`:    chainECB,
+	`cbc`:    chainCBC,
+	`cfb1`:   chainCFB1,
+	`cfb8`:   chainCFB8,
+	`cfb128`: chainCFB128,
+	`ofb`:    chainOFB,
+}
+
+// keySizes maps the MySQL key lengths to the key sizes in bytes.
+var keySizes = map[string]int{
+	`128`: 16,
+	`192`: 24,
+	`256`: 32,
+}
+
+// ******** Public creation function ********
+
+// NewCrypter creates a Crypter for a value of the MySQL system variable block_encryption_mode,
+// e.g. "aes-128-ecb" or "aes-256-cbc".
+func NewCrypter(blockEncryptionMode string) (*Crypter, error) {
+	info, err := parseBlockEncryptionMode(blockEncryptionMode)
+	if err != nil {
+		return nil, err
+	}
+
+	var padder *blockpad.BlockPad
+	padder, err = blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Crypter{info: info, padder: padder}, nil
+}
+
+// ******** Public functions ********
+
+// Encrypt encrypts str with keyStr like AES_ENCRYPT(str, keyStr, initVector).
+// initVector is ignored in ECB mode and may be nil.
+func (c *Crypter) Encrypt(str []byte, keyStr []byte, initVector []byte) ([]byte, error) {
+	block, iv, err := c.prepare(keyStr, initVector)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.info.chain {
+	case chainECB:
+		encrypter, _ := padmode.NewPaddedEncrypter(blockmode.NewECBEncrypter(block), c.padder)
+		return encrypter.Seal(nil, str), nil
+
+	case chainCBC:
+		encrypter, _ := padmode.NewPaddedEncrypter(cipher.NewCBCEncrypter(block, iv), c.padder)
+		return encrypter.Seal(nil, str), nil
+
+	default:
+		result := make([]byte, len(str))
+		c.newStream(block, iv, false).XORKeyStream(result, str)
+		return result, nil
+	}
+}
+
+// Decrypt decrypts cryptStr with keyStr like AES_DECRYPT(cryptStr, keyStr, initVector).
+// initVector is ignored in ECB mode and may be nil.
+// If MySQL would return NULL, ErrDecryptionFailed is returned.
+func (c *Crypter) Decrypt(cryptStr []byte, keyStr []byte, initVector []byte) ([]byte, error) {
+	block, iv, err := c.prepare(keyStr, initVector)
+	if err != nil {
+		return nil, err
+	}
+
+	var decrypter *padmode.PaddedDecrypter
+	switch c.info.chain {
+	case chainECB:
+		decrypter, _ = padmode.NewPaddedDecrypter(blockmode.NewECBDecrypter(block), c.padder)
+
+	case chainCBC:
+		decrypter, _ = padmode.NewPaddedDecrypter(cipher.NewCBCDecrypter(block, iv), c.padder)
+
+	default:
+		result := make([]byte, len(cryptStr))
+		c.newStream(block, iv, true).XORKeyStream(result, cryptStr)
+		return result, nil
+	}
+
+	var result []byte
+	result, err = decrypter.Open(nil, cryptStr)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return result, nil
+}
+
+// ******** Private functions ********
+
+// parseBlockEncryptionMode parses a value of block_encryption_mode.
+func parseBlockEncryptionMode(blockEncryptionMode string) (*modeInfo, error) {
+	parts := strings.Split(strings.ToLower(blockEncryptionMode), `-`)
+	if len(parts) != 3 || parts[0] != `aes` {
+		return nil, ErrInvalidBlockEncryptionMode
+	}
+
+	keySize, isValidKeySize := keySizes[parts[1]]
+	chain, isValidChain := chainNames[parts[2]]
+	if !isValidKeySize || !isValidChain {
+		return nil, ErrInvalidBlockEncryptionMode
+	}
+
+	return &modeInfo{keySize: keySize, chain: chain}, nil
+}
+
+// prepare folds the key string into a key, creates the block cipher and checks the initialization vector.
+func (c *Crypter) prepare(keyStr []byte, initVector []byte) (cipher.Block, []byte, error) {
+	var iv []byte
+	if c.info.chain != chainECB {
+		if len(initVector) < aes.BlockSize {
+			return nil, nil, blockpad.ErrInvalidIV
+		}
+
+		iv = initVector[:aes.BlockSize]
+	}
+
+	block, err := aes.NewCipher(foldKey(keyStr, c.info.keySize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, iv, nil
+}
+
+// newStream creates the stream for the stream modes CFB1, CFB8, CFB128 and OFB.
+// The modes aes-*-cfb128 and aes-*-ofb need the deprecated streams of crypto/cipher.
+func (c *Crypter) newStream(block cipher.Block, iv []byte, isDecrypter bool) cipher.Stream {
+	switch c.info.chain {
+	case chainCFB1:
+		return newCFBS(block, iv, 1, isDecrypter)
+
+	case chainCFB8:
+		return newCFBS(block, iv, 8, isDecrypter)
+
+	case chainCFB128:
+		if isDecrypter {
+			return cipher.NewCFBDecrypter(block, iv)
+		}
+
+		return cipher.NewCFBEncrypter(block, iv)
+
+	default:
+		return cipher.NewOFB(block, iv)
+	}
+}
+
+// foldKey folds the key string into a key of size keySize like MySQL does.
+// All bytes of the key string are XORed into a zero-initialized buffer, wrapping around at the end.
+func foldKey(keyStr []byte, keySize int) []byte {
+	result := make([]byte, keySize)
+
+	for i, b := range keyStr {
+		result[i%keySize] ^= b
+	}
+
+	return result
+}
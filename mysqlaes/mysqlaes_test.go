@@ -0,0 +1,232 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqlaes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	"testing"
+)
+
+// ******** Private types ********
+
+// vector holds the result of AES_ENCRYPT for one block encryption mode.
+type vector struct {
+	mode      string
+	encrypted string
+}
+
+// ******** Private constants ********
+
+// loopCount is the number of random tests.
+const loopCount = 100
+
+// These are the inputs of the vectors.
+const (
+	vectorKey  = `secret key that is longer than sixteen bytes`
+	vectorIV   = `0123456789abcdefXYZ`
+	vectorData = `MySQL column data`
+)
+
+// ******** Private variables ********
+
+// vectors contains the expected results of
+//
+//	SET block_encryption_mode = '<mode>';
+//	SELECT HEX(AES_ENCRYPT('MySQL column data', 'secret key that is longer than sixteen bytes', '0123456789abcdefXYZ'));
+//
+// MySQL folds the key and then encrypts with OpenSSL, so the vectors have been computed
+// with the openssl command line tool from the folded keys in foldedKeys, the IV and the mode.
+// They have not been generated by a MySQL server.
+var vectors = []vector{
+	{mode: `aes-128-ecb`, encrypted: `9affdadc5956266289b616384bf3f9af5d74bb410b780a6bc4c2d10adba2a001`},
+	{mode: `aes-128-cbc`, encrypted: `822b98ffe0b066227054974f1aeeb843ea5644fc5761070e2a339e857481e8cf`},
+	{mode: `aes-128-cfb1`, encrypted: `58804346cdfa9ce84b2026aed3281af3bb`},
+	{mode: `aes-128-cfb8`, encrypted: `33d0d4a86bc6f4ec583c118d84cad0ae82`},
+	{mode: `aes-128-cfb128`, encrypted: `33878bcd917a2a4dc484ebd1b1713363c7`},
+	{mode: `aes-128-ofb`, encrypted: `33878bcd917a2a4dc484ebd1b1713363d0`},
+	{mode: `aes-192-ecb`, encrypted: `45cfae1483c60ae486d38f53dd202e2bc9c294a5f89e19df67203dd7486955d0`},
+	{mode: `aes-192-cbc`, encrypted: `6a62bcfa648afa9976e5a546938cfaabccb235a71fa6875f199dfbfe91931123`},
+	{mode: `aes-192-cfb1`, encrypted: `7dc4d4762fb6fd63095b2c7fa65e1685e6`},
+	{mode: `aes-192-cfb8`, encrypted: `32de653dcf8e13284fd5e630742baa96bd`},
+	{mode: `aes-192-cfb128`, encrypted: `32d7cf7c81d169fa8eac743bcf6bdcbbd4`},
+	{mode: `aes-192-ofb`, encrypted: `32d7cf7c81d169fa8eac743bcf6bdcbbeb`},
+	{mode: `aes-256-ecb`, encrypted: `d6541206435fa1af6ef4345adf0ef5388bdf237f1a2c363bb438023aa6effcc0`},
+	{mode: `aes-256-cbc`, encrypted: `6463fe82745dad8b1ac9b6cee789c482037a73f5e34075420673ac78a606e882`},
+	{mode: `aes-256-cfb1`, encrypted: `fdd1c1520b4e4565db9a3023c82b609d32`},
+	{mode: `aes-256-cfb8`, encrypted: `df7517b9febf82e380fb851a74984926e5`},
+	{mode: `aes-256-cfb128`, encrypted: `dfc0517687d8b7cec16ebca387e9a63d0d`},
+	{mode: `aes-256-ofb`, encrypted: `dfc0517687d8b7cec16ebca387e9a63d06`},
+}
+
+// foldedKeys contains the keys that MySQL folds from vectorKey for the key sizes.
+// They have been computed independently of foldKey.
+var foldedKeys = map[int]string{
+	16: `736e377b6f74676c6e2d316f090f5453`,
+	24: `0145171a041a00180c0154110d0f54421007451f6f6e6765`,
+	32: `1a1d1717001a00091c0d4507686174206973206c6f6e676572207468616e2073`,
+}
+
+// ******** Vector tests ********
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		c, err := NewCrypter(v.mode)
+		if err != nil {
+			t.Fatalf(`%s: could not create crypter: %v`, v.mode, err)
+		}
+
+		var encrypted []byte
+		encrypted, err = c.Encrypt([]byte(vectorData), []byte(vectorKey), []byte(vectorIV))
+		if err != nil {
+			t.Fatalf(`%s: encryption failed: %v`, v.mode, err)
+		}
+		if hex.EncodeToString(encrypted) != v.encrypted {
+			t.Fatalf(`%s: encrypted data '%x' differs from vector '%s'`, v.mode, encrypted, v.encrypted)
+		}
+
+		var decrypted []byte
+		decrypted, err = c.Decrypt(encrypted, []byte(vectorKey), []byte(vectorIV))
+		if err != nil {
+			t.Fatalf(`%s: decryption failed: %v`, v.mode, err)
+		}
+		if string(decrypted) != vectorData {
+			t.Fatalf(`%s: decrypted data '%s' differs from vector data`, v.mode, decrypted)
+		}
+	}
+}
+
+func TestDefaultMode(t *testing.T) {
+	c, err := NewCrypter(DefaultBlockEncryptionMode)
+	if err != nil {
+		t.Fatalf(`Could not create crypter: %v`, err)
+	}
+
+	// SELECT HEX(AES_ENCRYPT('text', 'password'))
+	var encrypted []byte
+	encrypted, err = c.Encrypt([]byte(`text`), []byte(`password`), nil)
+	if err != nil {
+		t.Fatalf(`Encryption failed: %v`, err)
+	}
+	if hex.EncodeToString(encrypted) != `f6bd0fa8dcb7f8cd4a2faabc54668044` {
+		t.Fatalf(`Encrypted data '%x' is wrong`, encrypted)
+	}
+}
+
+// ******** Functional tests ********
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := []byte(vectorKey)
+	iv := []byte(vectorIV)
+
+	for _, v := range vectors {
+		c, _ := NewCrypter(v.mode)
+
+		for dataLen := 0; dataLen <= loopCount; dataLen++ {
+			data := testhelper.MakeTestSlice(dataLen)
+
+			encrypted, err := c.Encrypt(data, key, iv)
+			if err != nil {
+				t.Fatalf(`%s: encryption of %d bytes failed: %v`, v.mode, dataLen, err)
+			}
+
+			var decrypted []byte
+			decrypted, err = c.Decrypt(encrypted, key, iv)
+			if err != nil {
+				t.Fatalf(`%s: decryption of %d bytes failed: %v`, v.mode, dataLen, err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Fatalf(`%s: decrypted data of %d bytes differs from data`, v.mode, dataLen)
+			}
+		}
+	}
+}
+
+func TestKeyFolding(t *testing.T) {
+	for keySize, foldedKey := range foldedKeys {
+		key := foldKey([]byte(vectorKey), keySize)
+		if hex.EncodeToString(key) != foldedKey {
+			t.Fatalf(`Folded key '%x' differs from '%s'`, key, foldedKey)
+		}
+	}
+
+	key := foldKey([]byte(`0123456789abcdef0123456789abcdef`), 16)
+	for _, b := range key {
+		if b != 0 {
+			t.Fatalf(`Folding a repeated key did not result in a zero key: %x`, key)
+		}
+	}
+
+	key = foldKey([]byte(`short`), 16)
+	if string(key[:5]) != `short` || !bytes.Equal(key[5:], make([]byte, 11)) {
+		t.Fatalf(`Folding a short key is wrong: %x`, key)
+	}
+}
+
+// ******** Error tests ********
+
+func TestInvalidBlockEncryptionMode(t *testing.T) {
+	for _, mode := range []string{``, `aes`, `aes-128`, `des-128-ecb`, `aes-64-ecb`, `aes-128-ctr`, `aes-128-ecb-x`} {
+		_, err := NewCrypter(mode)
+		if !errors.Is(err, ErrInvalidBlockEncryptionMode) {
+			t.Fatalf(`Invalid block encryption mode '%s' was not detected: %v`, mode, err)
+		}
+	}
+
+	_, err := NewCrypter(`AES-256-CBC`)
+	if err != nil {
+		t.Fatalf(`Upper case block encryption mode was not accepted: %v`, err)
+	}
+}
+
+func TestInvalidIV(t *testing.T) {
+	c, _ := NewCrypter(`aes-128-cbc`)
+
+	_, err := c.Encrypt([]byte(vectorData), []byte(vectorKey), []byte(`0123456789abcde`))
+	if !errors.Is(err, blockpad.ErrInvalidIV) {
+		t.Fatalf(`Short IV was not detected on encryption: %v`, err)
+	}
+
+	_, err = c.Decrypt(make([]byte, 16), []byte(vectorKey), nil)
+	if !errors.Is(err, blockpad.ErrInvalidIV) {
+		t.Fatalf(`Missing IV was not detected on decryption: %v`, err)
+	}
+}
+
+func TestDecryptionFailed(t *testing.T) {
+	c, _ := NewCrypter(DefaultBlockEncryptionMode)
+
+	for _, encrypted := range [][]byte{nil, make([]byte, 15), make([]byte, 17)} {
+		_, err := c.Decrypt(encrypted, []byte(vectorKey), nil)
+		if !errors.Is(err, ErrDecryptionFailed) {
+			t.Fatalf(`Invalid length %d was not detected: %v`, len(encrypted), err)
+		}
+	}
+
+	encrypted, _ := c.Encrypt([]byte(vectorData), []byte(vectorKey), nil)
+	_, err := c.Decrypt(encrypted, []byte(`wrong key`), nil)
+	if !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf(`Wrong key was not detected: %v`, err)
+	}
+}
@@ -0,0 +1,281 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package opensslenc implements the file format of the OpenSSL "enc" command
+// for AES in CBC mode with a salt, e.g. "openssl enc -aes-256-cbc -pbkdf2".
+//
+// The encrypted data start with the magic "Salted__", followed by an 8 byte salt
+// and the encrypted PKCS#7 padded data.
+// Key and initialization vector are derived from a password and the salt
+// either with EVP_BytesToKey or with PBKDF2.
+//
+// ATTENTION: The format has no integrity protection. Decryption errors caused
+// by an invalid padding form a padding oracle. The format should only be used
+// for compatibility with existing files.
+package opensslenc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/bytestokey"
+	"github.com/xformerfhs/blockpad/internal/pbkdf2"
+	"github.com/xformerfhs/blockpad/padmode"
+	"io"
+)
+
+// ******** Public types ********
+
+// KeyDerivation is the type that holds key derivation functions.
+type KeyDerivation byte
+
+// Crypter encrypts and decrypts data in the OpenSSL "enc" format with one password.
+//
+// A Crypter is safe for concurrent use by multiple goroutines, as it is used read-only.
+type Crypter struct {
+	password      []byte
+	keySize       int
+	keyDerivation KeyDerivation
+	iterations    int
+	padder        *blockpad.BlockPad
+}
+
+// ******** Public constants ********
+
+// These are the valid key derivation functions.
+const (
+	// BytesToKeyMD5 is EVP_BytesToKey with MD5. It is the legacy default of OpenSSL before V1.1.0 ("-md md5").
+	BytesToKeyMD5 KeyDerivation = iota
+
+	// BytesToKeySHA256 is EVP_BytesToKey with SHA-256. It is the default of OpenSSL since V1.1.0 if "-pbkdf2" is not specified.
+	BytesToKeySHA256
+
+	// PBKDF2SHA256 is PBKDF2 with HMAC-SHA256 ("-pbkdf2").
+	PBKDF2SHA256
+)
+
+// DefaultIterations is the default iteration count of OpenSSL for PBKDF2 ("-iter").
+const DefaultIterations = 10000
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidHeader means that the data do not start with "Salted__" and a salt.
+	ErrInvalidHeader = errors.New(`invalid OpenSSL header`)
+
+	// ErrInvalidKeyDerivation means that the key derivation function is invalid.
+	ErrInvalidKeyDerivation = errors.New(`invalid key derivation`)
+
+	// ErrInvalidIterations means that the iteration count is not positive.
+	ErrInvalidIterations = errors.New(`invalid iteration count`)
+)
+
+// ******** Private constants ********
+
+// magic is the start of the header.
+const magic = `Salted__`
+
+// saltSize is the size of the salt.
+const saltSize = 8
+
+// headerSize is the size of the header.
+const headerSize = len(magic) + saltSize
+
+// ******** Public creation function ********
+
+// NewCrypter creates a Crypter for AES in CBC mode.
+// keySize is the AES key size in bytes, i.e. 16, 24 or 32.
+// iterations is the PBKDF2 iteration count. It is ignored for EVP_BytesToKey.
+func NewCrypter(password []byte, keySize int, keyDerivation KeyDerivation, iterations int) (*Crypter, error) {
+	if keySize != 16 && keySize != 24 && keySize != 32 {
+		return nil, aes.KeySizeError(keySize)
+	}
+
+	if keyDerivation > PBKDF2SHA256 {
+		return nil, ErrInvalidKeyDerivation
+	}
+
+	if keyDerivation == PBKDF2SHA256 && iterations < 1 {
+		return nil, ErrInvalidIterations
+	}
+
+	padder, err := blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Crypter{
+		password:      append([]byte(nil), password...),
+		keySize:       keySize,
+		keyDerivation: keyDerivation,
+		iterations:    iterations,
+		padder:        padder,
+	}, nil
+}
+
+// ******** Public functions ********
+
+// Encrypt encrypts data with a random salt.
+func (c *Crypter) Encrypt(data []byte) ([]byte, error) {
+	header, mode, err := c.newEncrypter()
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypter *padmode.PaddedEncrypter
+	encrypter, err = padmode.NewPaddedEncrypter(mode, c.padder)
+	if err != nil {
+		return nil, err
+	}
+
+	return encrypter.Seal(header, data), nil
+}
+
+// Decrypt decrypts data.
+func (c *Crypter) Decrypt(data []byte) ([]byte, error) {
+	if len(data) < headerSize {
+		return nil, ErrInvalidHeader
+	}
+
+	mode, err := c.newDecrypter(data[:headerSize])
+	if err != nil {
+		return nil, err
+	}
+
+	var decrypter *padmode.PaddedDecrypter
+	decrypter, err = padmode.NewPaddedDecrypter(mode, c.padder)
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypter.Open(nil, data[headerSize:])
+}
+
+// NewWriter creates a writer that writes the header with a random salt to w
+// and encrypts all data written to it.
+// The last block is written when the writer is closed.
+func (c *Crypter) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	header, mode, err := c.newEncrypter()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var writer *blockpad.Writer
+	writer, err = c.padder.NewCryptWriter(w, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// NewReader reads the header from r and creates a reader that decrypts the data read from r.
+func (c *Crypter) NewReader(r io.Reader) (io.Reader, error) {
+	header := make([]byte, headerSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrInvalidHeader
+		}
+
+		return nil, err
+	}
+
+	var mode cipher.BlockMode
+	mode, err = c.newDecrypter(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader *blockpad.Reader
+	reader, err = c.padder.NewCryptReader(r, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// ******** Private functions ********
+
+// newEncrypter creates a header with a random salt and the corresponding CBC encrypter.
+func (c *Crypter) newEncrypter() ([]byte, cipher.BlockMode, error) {
+	header := make([]byte, headerSize)
+	copy(header, magic)
+
+	_, err := rand.Read(header[len(magic):])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, iv, err := c.deriveCipher(header[len(magic):])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, cipher.NewCBCEncrypter(block, iv), nil
+}
+
+// newDecrypter checks the header and creates the corresponding CBC decrypter.
+func (c *Crypter) newDecrypter(header []byte) (cipher.BlockMode, error) {
+	if !bytes.Equal(header[:len(magic)], []byte(magic)) {
+		return nil, ErrInvalidHeader
+	}
+
+	block, iv, err := c.deriveCipher(header[len(magic):])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewCBCDecrypter(block, iv), nil
+}
+
+// deriveCipher derives the key and the initialization vector from the password and the salt.
+// It returns the block cipher and the initialization vector.
+func (c *Crypter) deriveCipher(salt []byte) (cipher.Block, []byte, error) {
+	keyAndIVLen := c.keySize + aes.BlockSize
+
+	var keyAndIV []byte
+	switch c.keyDerivation {
+	case BytesToKeyMD5:
+		keyAndIV = bytestokey.Key(md5.New, c.password, salt, keyAndIVLen)
+	case BytesToKeySHA256:
+		keyAndIV = bytestokey.Key(sha256.New, c.password, salt, keyAndIVLen)
+	default:
+		keyAndIV = pbkdf2.Key(c.password, salt, c.iterations, keyAndIVLen, sha256.New)
+	}
+
+	block, err := aes.NewCipher(keyAndIV[:c.keySize])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, keyAndIV[c.keySize:], nil
+}
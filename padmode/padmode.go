@@ -0,0 +1,152 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package padmode implements block modes that pad and unpad automatically.
+//
+// It combines a [crypto/cipher/BlockMode] with a [github.com/xformerfhs/blockpad.BlockPad],
+// so that data of arbitrary length can be encrypted and decrypted with one call.
+package padmode
+
+import (
+	"crypto/cipher"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/slicehelper"
+)
+
+// ******** Public types ********
+
+// PaddedEncrypter pads and encrypts data with a block mode.
+//
+// A block mode keeps its state between calls, e.g. CBC chains the blocks over
+// all calls. So, a new PaddedEncrypter with a fresh block mode should be created for every message.
+type PaddedEncrypter struct {
+	mode   cipher.BlockMode
+	padder *blockpad.BlockPad
+}
+
+// PaddedDecrypter decrypts and unpads data with a block mode.
+//
+// A block mode keeps its state between calls, e.g. CBC chains the blocks over
+// all calls. So, a new PaddedDecrypter with a fresh block mode should be created for every message.
+type PaddedDecrypter struct {
+	mode   cipher.BlockMode
+	padder *blockpad.BlockPad
+}
+
+// ******** Public creation functions ********
+
+// NewPaddedEncrypter creates a PaddedEncrypter.
+// The block size of the block mode must be the same as the block size of the padder.
+func NewPaddedEncrypter(mode cipher.BlockMode, padder *blockpad.BlockPad) (*PaddedEncrypter, error) {
+	err := checkBlockSize(mode, padder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaddedEncrypter{mode: mode, padder: padder}, nil
+}
+
+// NewPaddedDecrypter creates a PaddedDecrypter.
+// The block size of the block mode must be the same as the block size of the padder.
+func NewPaddedDecrypter(mode cipher.BlockMode, padder *blockpad.BlockPad) (*PaddedDecrypter, error) {
+	err := checkBlockSize(mode, padder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaddedDecrypter{mode: mode, padder: padder}, nil
+}
+
+// ******** Public functions ********
+
+// Seal pads and encrypts plaintext and appends the result to dst.
+// It returns the updated slice.
+// To reuse plaintext's storage for the encrypted output, use plaintext[:0] as dst.
+// Otherwise, the remaining capacity of dst must not overlap plaintext.
+func (pe *PaddedEncrypter) Seal(dst []byte, plaintext []byte) []byte {
+	// A prefix block shifts the data, so it has to be padded as a whole.
+	if pe.padder.PrefixLen() != 0 {
+		paddedData := pe.padder.Pad(plaintext)
+		result, out := slicehelper.ForAppend(dst, len(paddedData))
+		pe.mode.CryptBlocks(out, paddedData)
+
+		return result
+	}
+
+	// 1. Pad the last block without copying the full blocks.
+	fullBlockData, lastBlock := pe.padder.PadLastBlock(plaintext)
+	fullBlockDataLen := len(fullBlockData)
+
+	// 2. Encrypt directly into the destination.
+	result, out := slicehelper.ForAppend(dst, fullBlockDataLen+len(lastBlock))
+	pe.mode.CryptBlocks(out[:fullBlockDataLen], fullBlockData)
+	pe.mode.CryptBlocks(out[fullBlockDataLen:], lastBlock)
+
+	return result
+}
+
+// BlockSize returns the block size of the encrypter.
+func (pe *PaddedEncrypter) BlockSize() int {
+	return pe.mode.BlockSize()
+}
+
+// Open decrypts and unpads ciphertext and appends the result to dst.
+// It returns the updated slice.
+// To reuse ciphertext's storage for the decrypted output, use ciphertext[:0] as dst.
+// Otherwise, the remaining capacity of dst must not overlap ciphertext.
+func (pd *PaddedDecrypter) Open(dst []byte, ciphertext []byte) ([]byte, error) {
+	ciphertextLen := len(ciphertext)
+	if ciphertextLen%pd.mode.BlockSize() != 0 {
+		return nil, blockpad.ErrInvalidPaddedDataLen
+	}
+
+	// 1. Decrypt directly into the destination.
+	result, out := slicehelper.ForAppend(dst, ciphertextLen)
+	pd.mode.CryptBlocks(out, ciphertext)
+
+	// 2. Unpad the decrypted data.
+	unpaddedData, err := pd.padder.Unpad(out)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Move the data behind a prefix block to the front.
+	if pd.padder.PrefixLen() != 0 {
+		copy(out, unpaddedData)
+	}
+
+	return result[:len(dst)+len(unpaddedData)], nil
+}
+
+// BlockSize returns the block size of the decrypter.
+func (pd *PaddedDecrypter) BlockSize() int {
+	return pd.mode.BlockSize()
+}
+
+// ******** Private functions ********
+
+// checkBlockSize checks if the block sizes of the block mode and the padder are the same.
+func checkBlockSize(mode cipher.BlockMode, padder *blockpad.BlockPad) error {
+	if mode.BlockSize() != padder.BlockSize() {
+		return blockpad.ErrInvalidBlockMode
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package padmode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	mrand "math/rand"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the number of times a functional test is to be performed.
+const loopCount = 100
+
+// ******** Functional tests ********
+
+func TestSealOpen(t *testing.T) {
+	aesCipher, iv := makeAESCipher(t)
+
+	for padType := blockpad.PKCS7; padType <= blockpad.NotLastByte; padType++ {
+		padder, err := blockpad.NewBlockPadding(padType, aes.BlockSize)
+		if err != nil {
+			t.Fatalf(`Error creating BlockPad with pad type %d: %v`, padType, err)
+		}
+
+		for i := 0; i < loopCount; i++ {
+			data := testhelper.MakeTestSlice(mrand.Intn(200))
+
+			encrypter, decrypter := makeEncrypterAndDecrypter(t, aesCipher, iv, padder)
+
+			ciphertext := encrypter.Seal(nil, data)
+
+			// Paddings with random bytes can not be compared.
+			if padType != blockpad.ISO10126 && padType != blockpad.ArbitraryTailByte {
+				expected := padder.Pad(data)
+				cipher.NewCBCEncrypter(aesCipher, iv).CryptBlocks(expected, expected)
+				if !bytes.Equal(ciphertext, expected) {
+					t.Fatalf(`%s: Seal result differs from encrypted padded data`, padder.String())
+				}
+			}
+
+			var decryptedData []byte
+			decryptedData, err = decrypter.Open(nil, ciphertext)
+			if err != nil {
+				t.Fatalf(`%s: Open failed: %v`, padder.String(), err)
+			}
+			if !bytes.Equal(decryptedData, data) {
+				t.Fatalf(`%s: decrypted data differs from data`, padder.String())
+			}
+		}
+	}
+}
+
+func TestSealOpenInPlace(t *testing.T) {
+	aesCipher, iv := makeAESCipher(t)
+
+	padder, err := blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad: %v`, err)
+	}
+
+	encrypter, decrypter := makeEncrypterAndDecrypter(t, aesCipher, iv, padder)
+
+	data := []byte(`Beware the ides of march`)
+	buffer := make([]byte, len(data), len(data)+aes.BlockSize)
+	copy(buffer, data)
+
+	prefix := []byte(`prefix`)
+	ciphertext := encrypter.Seal(buffer[:0], buffer)
+
+	var decryptedData []byte
+	decryptedData, err = decrypter.Open(prefix, ciphertext)
+	if err != nil {
+		t.Fatalf(`Open failed: %v`, err)
+	}
+	if !bytes.Equal(decryptedData, append([]byte(`prefix`), data...)) {
+		t.Fatalf(`Decrypted data '%s' is not the expected data`, decryptedData)
+	}
+}
+
+func TestSealOpenPrefixBlock(t *testing.T) {
+	aesCipher, iv := makeAESCipher(t)
+
+	padder, err := blockpad.NewBlockPadding(blockpad.ISO97971M3, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad: %v`, err)
+	}
+
+	for dataLen := 0; dataLen <= 3*aes.BlockSize; dataLen++ {
+		data := testhelper.MakeTestSlice(dataLen)
+		encrypter, decrypter := makeEncrypterAndDecrypter(t, aesCipher, iv, padder)
+
+		// Encrypt and decrypt in place.
+		buffer := bytes.Clone(data)
+		ciphertext := encrypter.Seal(buffer[:0], buffer)
+
+		expected := padder.Pad(data)
+		cipher.NewCBCEncrypter(aesCipher, iv).CryptBlocks(expected, expected)
+		if !bytes.Equal(ciphertext, expected) {
+			t.Fatalf(`%s: Seal result differs from encrypted padded data (dataLen=%d)`, padder.String(), dataLen)
+		}
+
+		var decryptedData []byte
+		decryptedData, err = decrypter.Open(ciphertext[:0], ciphertext)
+		if err != nil {
+			t.Fatalf(`%s: Open failed (dataLen=%d): %v`, padder.String(), dataLen, err)
+		}
+		if !bytes.Equal(decryptedData, data) {
+			t.Fatalf(`%s: decrypted data differs from data (dataLen=%d)`, padder.String(), dataLen)
+		}
+	}
+}
+
+// ******** Test invalid data ********
+
+func TestOpenWrongSize(t *testing.T) {
+	aesCipher, iv := makeAESCipher(t)
+
+	padder, err := blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad: %v`, err)
+	}
+
+	for _, dataLen := range []int{0, 1, aes.BlockSize + 3} {
+		_, decrypter := makeEncrypterAndDecrypter(t, aesCipher, iv, padder)
+
+		_, err = decrypter.Open(nil, testhelper.MakeTestSlice(dataLen))
+		if !errors.Is(err, blockpad.ErrInvalidPaddedDataLen) {
+			t.Fatalf(`Wrong error opening ciphertext with length %d: %v`, dataLen, err)
+		}
+	}
+}
+
+func TestWrongBlockSize(t *testing.T) {
+	padder, err := blockpad.NewBlockPadding(blockpad.PKCS7, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad: %v`, err)
+	}
+
+	desCipher, err := des.NewCipher(testhelper.MakeTestSlice(des.BlockSize))
+	if err != nil {
+		t.Fatalf(`Could not create DES cipher: %v`, err)
+	}
+
+	_, err = NewPaddedEncrypter(cipher.NewCBCEncrypter(desCipher, testhelper.MakeTestSlice(des.BlockSize)), padder)
+	if !errors.Is(err, blockpad.ErrInvalidBlockMode) {
+		t.Fatalf(`Wrong error creating encrypter with wrong block size: %v`, err)
+	}
+
+	_, err = NewPaddedDecrypter(cipher.NewCBCDecrypter(desCipher, testhelper.MakeTestSlice(des.BlockSize)), padder)
+	if !errors.Is(err, blockpad.ErrInvalidBlockMode) {
+		t.Fatalf(`Wrong error creating decrypter with wrong block size: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// makeAESCipher creates an AES cipher with a random key and a random iv.
+func makeAESCipher(t *testing.T) (cipher.Block, []byte) {
+	aesCipher, err := aes.NewCipher(testhelper.MakeTestSlice(32))
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	return aesCipher, testhelper.MakeTestSlice(aes.BlockSize)
+}
+
+// makeEncrypterAndDecrypter creates a CBC encrypter and decrypter with fresh block modes.
+func makeEncrypterAndDecrypter(t *testing.T, blockCipher cipher.Block, iv []byte, padder *blockpad.BlockPad) (*PaddedEncrypter, *PaddedDecrypter) {
+	encrypter, err := NewPaddedEncrypter(cipher.NewCBCEncrypter(blockCipher, iv), padder)
+	if err != nil {
+		t.Fatalf(`Could not create encrypter: %v`, err)
+	}
+
+	var decrypter *PaddedDecrypter
+	decrypter, err = NewPaddedDecrypter(cipher.NewCBCDecrypter(blockCipher, iv), padder)
+	if err != nil {
+		t.Fatalf(`Could not create decrypter: %v`, err)
+	}
+
+	return encrypter, decrypter
+}
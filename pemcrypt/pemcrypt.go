@@ -0,0 +1,282 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pemcrypt implements the encryption and decryption of PEM blocks as specified in RFC 1423.
+//
+// It is a replacement for the deprecated functions x509.EncryptPEMBlock,
+// x509.DecryptPEMBlock and x509.IsEncryptedPEMBlock.
+// The PKCS#7 padding is removed in constant time.
+//
+// ATTENTION: RFC 1423 encryption has no integrity protection.
+// An invalid padding is reported as ErrIncorrectPassword, which may still act as a padding oracle.
+// It should only be used to migrate legacy keys to a better format, e.g. PKCS#8 with PBES2.
+package pemcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/bytestokey"
+	"github.com/xformerfhs/blockpad/padmode"
+	"io"
+	"strings"
+)
+
+// ******** Public types ********
+
+// PEMCipher is the type that holds the ciphers for PEM encryption.
+type PEMCipher byte
+
+// ******** Public constants ********
+
+// These are the valid ciphers.
+const (
+	// minCipher is a helper constant and always contains the minimum defined cipher constant.
+	// It must always be the first constant in this const block!
+	minCipher PEMCipher = 0
+
+	// CipherDES is DES in CBC mode.
+	CipherDES PEMCipher = iota - 1
+
+	// Cipher3DES is Triple-DES in CBC mode.
+	Cipher3DES
+
+	// CipherAES128 is AES-128 in CBC mode.
+	CipherAES128
+
+	// CipherAES192 is AES-192 in CBC mode.
+	CipherAES192
+
+	// CipherAES256 is AES-256 in CBC mode.
+	CipherAES256
+
+	// maxCipher is a helper constant and always contains the maximum defined cipher constant.
+	// It must always be the last constant in this const block!
+	maxCipher = iota - 2
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrIncorrectPassword means that the password is incorrect.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrIncorrectPassword = errors.New(`decryption password incorrect`)
+
+	// ErrNotEncrypted means that the PEM block is not encrypted.
+	ErrNotEncrypted = errors.New(`PEM block is not encrypted`)
+
+	// ErrInvalidDEKInfo means that the DEK-Info header is malformed.
+	ErrInvalidDEKInfo = errors.New(`malformed DEK-Info header`)
+
+	// ErrUnknownCipher means that the cipher in the DEK-Info header is not supported.
+	ErrUnknownCipher = errors.New(`unknown encryption cipher`)
+
+	// ErrInvalidCipher means that the supplied cipher is invalid.
+	ErrInvalidCipher = errors.New(`invalid cipher`)
+
+	// ErrInvalidEncryptedDataLen means that the length of the encrypted data is not a multiple of the block size.
+	ErrInvalidEncryptedDataLen = errors.New(`encrypted data length is not a multiple of the block size`)
+)
+
+// ******** Private types ********
+
+// cipherInfo holds the parameters of a cipher.
+type cipherInfo struct {
+	name      string
+	keySize   int
+	blockSize int
+	newCipher func([]byte) (cipher.Block, error)
+}
+
+// ******** Private constants ********
+
+// These are the header names and values.
+const (
+	procTypeHeader = `Proc-Type`
+	procTypeValue  = `4,ENCRYPTED`
+	dekInfoHeader  = `DEK-Info`
+)
+
+// saltSize is the size of the salt, i.e. the part of the iv that is used for the key derivation.
+const saltSize = 8
+
+// cipherImplementation holds the parameters of the ciphers.
+var cipherImplementation = []cipherInfo{
+	{name: `DES-CBC`, keySize: 8, blockSize: des.BlockSize, newCipher: des.NewCipher},
+	{name: `DES-EDE3-CBC`, keySize: 24, blockSize: des.BlockSize, newCipher: des.NewTripleDESCipher},
+	{name: `AES-128-CBC`, keySize: 16, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{name: `AES-192-CBC`, keySize: 24, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{name: `AES-256-CBC`, keySize: 32, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+}
+
+// ******** Public functions ********
+
+// IsEncryptedPEMBlock returns whether the PEM block is password encrypted according to RFC 1423.
+func IsEncryptedPEMBlock(b *pem.Block) bool {
+	_, hasDEKInfo := b.Headers[dekInfoHeader]
+
+	return hasDEKInfo && b.Headers[procTypeHeader] == procTypeValue
+}
+
+// DecryptPEMBlock decrypts a PEM block that is encrypted according to RFC 1423 with the password.
+// It returns the decrypted DER bytes.
+func DecryptPEMBlock(b *pem.Block, password []byte) ([]byte, error) {
+	if !IsEncryptedPEMBlock(b) {
+		return nil, ErrNotEncrypted
+	}
+
+	// 1. Parse the DEK-Info header.
+	cipherName, hexIV, found := strings.Cut(b.Headers[dekInfoHeader], `,`)
+	if !found {
+		return nil, ErrInvalidDEKInfo
+	}
+
+	info := cipherByName(strings.TrimSpace(cipherName))
+	if info == nil {
+		return nil, ErrUnknownCipher
+	}
+
+	iv, err := hex.DecodeString(strings.TrimSpace(hexIV))
+	if err != nil || len(iv) != info.blockSize {
+		return nil, ErrInvalidDEKInfo
+	}
+
+	if len(b.Bytes) == 0 || len(b.Bytes)%info.blockSize != 0 {
+		return nil, ErrInvalidEncryptedDataLen
+	}
+
+	// 2. Decrypt and unpad.
+	var decrypter *padmode.PaddedDecrypter
+	decrypter, err = info.newDecrypter(password, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+	result, err = decrypter.Open(nil, b.Bytes)
+	if err != nil {
+		return nil, ErrIncorrectPassword
+	}
+
+	return result, nil
+}
+
+// EncryptPEMBlock returns a PEM block of the specified type holding the
+// data encrypted with the specified cipher and password according to RFC 1423.
+// The initialization vector is read from rand.
+func EncryptPEMBlock(rand io.Reader, blockType string, data []byte, password []byte, pemCipher PEMCipher) (*pem.Block, error) {
+	if pemCipher > maxCipher {
+		return nil, ErrInvalidCipher
+	}
+
+	info := &cipherImplementation[pemCipher]
+
+	// 1. Create a random initialization vector.
+	iv := make([]byte, info.blockSize)
+	_, err := io.ReadFull(rand, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Pad and encrypt.
+	var encrypter *padmode.PaddedEncrypter
+	encrypter, err = info.newEncrypter(password, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pem.Block{
+		Type: blockType,
+		Headers: map[string]string{
+			procTypeHeader: procTypeValue,
+			dekInfoHeader:  info.name + `,` + strings.ToUpper(hex.EncodeToString(iv)),
+		},
+		Bytes: encrypter.Seal(nil, data),
+	}, nil
+}
+
+// String yields the name of the cipher as it is used in the DEK-Info header.
+// It implements the Stringer interface.
+func (c PEMCipher) String() string {
+	if c > maxCipher {
+		return `invalid`
+	}
+
+	return cipherImplementation[c].name
+}
+
+// ******** Private functions ********
+
+// cipherByName returns the cipher information for a DEK-Info cipher name.
+// It returns nil if there is no cipher with this name.
+func cipherByName(name string) *cipherInfo {
+	for i := range cipherImplementation {
+		if cipherImplementation[i].name == name {
+			return &cipherImplementation[i]
+		}
+	}
+
+	return nil
+}
+
+// newBlock derives the key from the password and the first 8 bytes of the iv
+// and creates the block cipher and the padder.
+func (ci *cipherInfo) newBlock(password []byte, iv []byte) (cipher.Block, *blockpad.BlockPad, error) {
+	key := bytestokey.Key(md5.New, password, iv[:saltSize], ci.keySize)
+
+	block, err := ci.newCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var padder *blockpad.BlockPad
+	padder, err = blockpad.NewBlockPadding(blockpad.PKCS7, ci.blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, padder, nil
+}
+
+// newEncrypter creates a padded CBC encrypter.
+func (ci *cipherInfo) newEncrypter(password []byte, iv []byte) (*padmode.PaddedEncrypter, error) {
+	block, padder, err := ci.newBlock(password, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return padmode.NewPaddedEncrypter(cipher.NewCBCEncrypter(block, iv), padder)
+}
+
+// newDecrypter creates a padded CBC decrypter.
+func (ci *cipherInfo) newDecrypter(password []byte, iv []byte) (*padmode.PaddedDecrypter, error) {
+	block, padder, err := ci.newBlock(password, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return padmode.NewPaddedDecrypter(cipher.NewCBCDecrypter(block, iv), padder)
+}
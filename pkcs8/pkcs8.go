@@ -0,0 +1,450 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkcs8 implements the encryption and decryption of PKCS#8 private keys
+// with the password based encryption scheme PBES2 as specified in RFC 8018.
+//
+// The key derivation function is PBKDF2 with an HMAC-SHA-1 or HMAC-SHA-2 pseudorandom function.
+// The encryption schemes are AES-128-CBC, AES-192-CBC, AES-256-CBC and DES-EDE3-CBC with PKCS#7 padding.
+// The PEM type of an encrypted private key is "ENCRYPTED PRIVATE KEY".
+package pkcs8
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/pbkdf2"
+	"github.com/xformerfhs/blockpad/padmode"
+	"hash"
+	"io"
+)
+
+// ******** Public types ********
+
+// Cipher is the type that holds the encryption schemes.
+type Cipher byte
+
+// PRF is the type that holds the pseudorandom functions of PBKDF2.
+type PRF byte
+
+// ******** Public constants ********
+
+// These are the valid encryption schemes.
+const (
+	// minCipher is a helper constant and always contains the minimum defined cipher constant.
+	// It must always be the first constant in this const block!
+	minCipher Cipher = 0
+
+	// AES128CBC is AES-128 in CBC mode.
+	AES128CBC Cipher = iota - 1
+
+	// AES192CBC is AES-192 in CBC mode.
+	AES192CBC
+
+	// AES256CBC is AES-256 in CBC mode.
+	AES256CBC
+
+	// DESEDE3CBC is Triple-DES in CBC mode. It should only be used for compatibility with legacy systems.
+	DESEDE3CBC
+
+	// maxCipher is a helper constant and always contains the maximum defined cipher constant.
+	// It must always be the last constant in this const block!
+	maxCipher = iota - 2
+)
+
+// These are the valid pseudorandom functions.
+const (
+	// minPRF is a helper constant and always contains the minimum defined pseudorandom function constant.
+	// It must always be the first constant in this const block!
+	minPRF PRF = 0
+
+	// HMACWithSHA1 is HMAC-SHA-1. It is the default of PBKDF2 and should only be used for compatibility with legacy systems.
+	HMACWithSHA1 PRF = iota - 1
+
+	// HMACWithSHA224 is HMAC-SHA-224.
+	HMACWithSHA224
+
+	// HMACWithSHA256 is HMAC-SHA-256.
+	HMACWithSHA256
+
+	// HMACWithSHA384 is HMAC-SHA-384.
+	HMACWithSHA384
+
+	// HMACWithSHA512 is HMAC-SHA-512.
+	HMACWithSHA512
+
+	// maxPRF is a helper constant and always contains the maximum defined pseudorandom function constant.
+	// It must always be the last constant in this const block!
+	maxPRF = iota - 2
+)
+
+// PEMType is the PEM block type of an encrypted private key.
+const PEMType = `ENCRYPTED PRIVATE KEY`
+
+// MaxIterations is the maximum iteration count of PBKDF2.
+// It limits the work that a manipulated encrypted private key can cause.
+const MaxIterations = 10_000_000
+
+// ******** Public errors ********
+
+var (
+	// ErrIncorrectPassword means that the password is incorrect.
+	// It is deliberately not stated what exactly is wrong so that
+	// an attacker does not obtain too much information.
+	ErrIncorrectPassword = errors.New(`decryption password incorrect`)
+
+	// ErrInvalidFormat means that the data is not a valid PBES2 encrypted private key.
+	ErrInvalidFormat = errors.New(`invalid encrypted private key format`)
+
+	// ErrUnsupportedAlgorithm means that an algorithm in the encrypted private key is not supported.
+	ErrUnsupportedAlgorithm = errors.New(`unsupported algorithm`)
+
+	// ErrInvalidCipher means that the supplied encryption scheme is invalid.
+	ErrInvalidCipher = errors.New(`invalid cipher`)
+
+	// ErrInvalidPRF means that the supplied pseudorandom function is invalid.
+	ErrInvalidPRF = errors.New(`invalid pseudorandom function`)
+
+	// ErrInvalidIterations means that the iteration count is not between 1 and MaxIterations.
+	ErrInvalidIterations = errors.New(`invalid iteration count`)
+
+	// ErrTooManyIterations means that the iteration count of an encrypted private key is greater than MaxIterations.
+	ErrTooManyIterations = errors.New(`too many iterations`)
+)
+
+// ******** Private types ********
+
+// algorithmIdentifier is the ASN.1 structure AlgorithmIdentifier.
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+// encryptedPrivateKeyInfo is the ASN.1 structure EncryptedPrivateKeyInfo of RFC 5958.
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm algorithmIdentifier
+	EncryptedData       []byte
+}
+
+// pbes2Params is the ASN.1 structure PBES2-params of RFC 8018.
+type pbes2Params struct {
+	KeyDerivationFunc algorithmIdentifier
+	EncryptionScheme  algorithmIdentifier
+}
+
+// pbkdf2Params is the ASN.1 structure PBKDF2-params of RFC 8018.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                 `asn1:"optional"`
+	PRF            algorithmIdentifier `asn1:"optional"`
+}
+
+// cipherInfo holds the parameters of an encryption scheme.
+type cipherInfo struct {
+	oid       asn1.ObjectIdentifier
+	keySize   int
+	blockSize int
+	newCipher func([]byte) (cipher.Block, error)
+}
+
+// prfInfo holds the parameters of a pseudorandom function.
+type prfInfo struct {
+	oid      asn1.ObjectIdentifier
+	hashFunc func() hash.Hash
+}
+
+// ******** Private constants ********
+
+// saltSize is the size of the salt of newly encrypted keys.
+const saltSize = 16
+
+// These are the object identifiers of the password based encryption.
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+)
+
+// cipherImplementation holds the parameters of the encryption schemes.
+var cipherImplementation = []cipherInfo{
+	{oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}, keySize: 16, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}, keySize: 24, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}, keySize: 32, blockSize: aes.BlockSize, newCipher: aes.NewCipher},
+	{oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}, keySize: 24, blockSize: des.BlockSize, newCipher: des.NewTripleDESCipher},
+}
+
+// prfImplementation holds the parameters of the pseudorandom functions.
+var prfImplementation = []prfInfo{
+	{oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}, hashFunc: sha1.New},
+	{oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}, hashFunc: sha256.New224},
+	{oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}, hashFunc: sha256.New},
+	{oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}, hashFunc: sha512.New384},
+	{oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}, hashFunc: sha512.New},
+}
+
+// ******** Public functions ********
+
+// EncryptPrivateKey encrypts a DER encoded PKCS#8 private key with PBES2 and the password.
+// It returns the DER encoded EncryptedPrivateKeyInfo.
+// Salt and initialization vector are read from rand.
+func EncryptPrivateKey(rand io.Reader, privateKey []byte, password []byte, encryptionCipher Cipher, prf PRF, iterations int) ([]byte, error) {
+	if encryptionCipher > maxCipher {
+		return nil, ErrInvalidCipher
+	}
+
+	if prf > maxPRF {
+		return nil, ErrInvalidPRF
+	}
+
+	if iterations < 1 || iterations > MaxIterations {
+		return nil, ErrInvalidIterations
+	}
+
+	ci := &cipherImplementation[encryptionCipher]
+	pi := &prfImplementation[prf]
+
+	// 1. Create random salt and initialization vector.
+	salt := make([]byte, saltSize)
+	_, err := io.ReadFull(rand, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, ci.blockSize)
+	_, err = io.ReadFull(rand, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Pad and encrypt.
+	var block cipher.Block
+	var padder *blockpad.BlockPad
+	block, padder, err = ci.newBlock(pbkdf2.Key(password, salt, iterations, ci.keySize, pi.hashFunc))
+	if err != nil {
+		return nil, err
+	}
+
+	encrypter, _ := padmode.NewPaddedEncrypter(cipher.NewCBCEncrypter(block, iv), padder)
+	encryptedData := encrypter.Seal(nil, privateKey)
+
+	// 3. Build the ASN.1 structures.
+	params := pbkdf2Params{Salt: salt, IterationCount: iterations}
+	// DER requires that the default value HMAC-SHA-1 is omitted.
+	if prf != HMACWithSHA1 {
+		params.PRF = algorithmIdentifier{Algorithm: pi.oid, Parameters: asn1.NullRawValue}
+	}
+
+	var kdfParams []byte
+	kdfParams, err = asn1.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var ivParam []byte
+	ivParam, err = asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemeParams []byte
+	schemeParams, err = asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: algorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  algorithmIdentifier{Algorithm: ci.oid, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		EncryptionAlgorithm: algorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: schemeParams}},
+		EncryptedData:       encryptedData,
+	})
+}
+
+// DecryptPrivateKey decrypts a DER encoded EncryptedPrivateKeyInfo with the password.
+// It returns the DER encoded PKCS#8 private key, which can be parsed with x509.ParsePKCS8PrivateKey.
+// If the iteration count is greater than MaxIterations, ErrTooManyIterations is returned.
+func DecryptPrivateKey(encryptedPrivateKey []byte, password []byte) ([]byte, error) {
+	// 1. Parse the ASN.1 structures.
+	var info encryptedPrivateKeyInfo
+	err := unmarshalAll(encryptedPrivateKey, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	var schemeParams pbes2Params
+	err = unmarshalAll(info.EncryptionAlgorithm.Parameters.FullBytes, &schemeParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if !schemeParams.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	var kdfParams pbkdf2Params
+	err = unmarshalAll(schemeParams.KeyDerivationFunc.Parameters.FullBytes, &kdfParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if kdfParams.IterationCount < 1 {
+		return nil, ErrInvalidFormat
+	}
+
+	if kdfParams.IterationCount > MaxIterations {
+		return nil, ErrTooManyIterations
+	}
+
+	// The default pseudorandom function is HMAC-SHA-1.
+	pi := &prfImplementation[HMACWithSHA1]
+	if len(kdfParams.PRF.Algorithm) != 0 {
+		pi = prfByOID(kdfParams.PRF.Algorithm)
+		if pi == nil {
+			return nil, ErrUnsupportedAlgorithm
+		}
+	}
+
+	ci := cipherByOID(schemeParams.EncryptionScheme.Algorithm)
+	if ci == nil {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	if kdfParams.KeyLength != 0 && kdfParams.KeyLength != ci.keySize {
+		return nil, ErrInvalidFormat
+	}
+
+	var iv []byte
+	err = unmarshalAll(schemeParams.EncryptionScheme.Parameters.FullBytes, &iv)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != ci.blockSize {
+		return nil, ErrInvalidFormat
+	}
+
+	encryptedDataLen := len(info.EncryptedData)
+	if encryptedDataLen == 0 || encryptedDataLen%ci.blockSize != 0 {
+		return nil, ErrInvalidFormat
+	}
+
+	// 2. Decrypt and unpad.
+	var block cipher.Block
+	var padder *blockpad.BlockPad
+	block, padder, err = ci.newBlock(pbkdf2.Key(password, kdfParams.Salt, kdfParams.IterationCount, ci.keySize, pi.hashFunc))
+	if err != nil {
+		return nil, err
+	}
+
+	decrypter, _ := padmode.NewPaddedDecrypter(cipher.NewCBCDecrypter(block, iv), padder)
+
+	var result []byte
+	result, err = decrypter.Open(nil, info.EncryptedData)
+	if err != nil {
+		return nil, ErrIncorrectPassword
+	}
+
+	return result, nil
+}
+
+// EncryptPEMBlock encrypts a DER encoded PKCS#8 private key with PBES2 and the password.
+// It returns a PEM block of type "ENCRYPTED PRIVATE KEY".
+func EncryptPEMBlock(rand io.Reader, privateKey []byte, password []byte, encryptionCipher Cipher, prf PRF, iterations int) (*pem.Block, error) {
+	encryptedPrivateKey, err := EncryptPrivateKey(rand, privateKey, password, encryptionCipher, prf, iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pem.Block{Type: PEMType, Bytes: encryptedPrivateKey}, nil
+}
+
+// DecryptPEMBlock decrypts a PEM block of type "ENCRYPTED PRIVATE KEY" with the password.
+// It returns the DER encoded PKCS#8 private key.
+func DecryptPEMBlock(b *pem.Block, password []byte) ([]byte, error) {
+	if b.Type != PEMType {
+		return nil, ErrInvalidFormat
+	}
+
+	return DecryptPrivateKey(b.Bytes, password)
+}
+
+// ******** Private functions ********
+
+// unmarshalAll parses DER data into a value and checks that there is no trailing data.
+func unmarshalAll(data []byte, value any) error {
+	rest, err := asn1.Unmarshal(data, value)
+	if err != nil || len(rest) != 0 {
+		return ErrInvalidFormat
+	}
+
+	return nil
+}
+
+// cipherByOID returns the encryption scheme for an object identifier.
+// It returns nil if there is no encryption scheme with this object identifier.
+func cipherByOID(oid asn1.ObjectIdentifier) *cipherInfo {
+	for i := range cipherImplementation {
+		if cipherImplementation[i].oid.Equal(oid) {
+			return &cipherImplementation[i]
+		}
+	}
+
+	return nil
+}
+
+// prfByOID returns the pseudorandom function for an object identifier.
+// It returns nil if there is no pseudorandom function with this object identifier.
+func prfByOID(oid asn1.ObjectIdentifier) *prfInfo {
+	for i := range prfImplementation {
+		if prfImplementation[i].oid.Equal(oid) {
+			return &prfImplementation[i]
+		}
+	}
+
+	return nil
+}
+
+// newBlock creates the block cipher with the key and the padder.
+func (ci *cipherInfo) newBlock(key []byte) (cipher.Block, *blockpad.BlockPad, error) {
+	block, err := ci.newCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var padder *blockpad.BlockPad
+	padder, err = blockpad.NewBlockPadding(blockpad.PKCS7, ci.blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, padder, nil
+}
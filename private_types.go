@@ -25,14 +25,26 @@ package blockpad
 // ******** Private types ********
 
 // fillerFunc is the type of a filler function.
-type fillerFunc func([]byte, int, []byte, int, int)
+// It is called with a nil prefix block to fill the last block.
+// If the padding prepends a prefix block, it is called with a nil last block to fill the prefix block.
+type fillerFunc func([]byte, []byte, int, []byte, int, int, int)
 
 // removerFunc is the type of a remover function.
-type removerFunc func([]byte, int, int) ([]byte, error)
+// It gets the prefix block, which is empty if the padding has none, the padded data ending with the last block,
+// the length of this data and the length of all padded data after the prefix block.
+type removerFunc func([]byte, []byte, int, int, int) ([]byte, error)
 
 // implementationInfo holds the data necessary for doing padding and unpadding.
 type implementationInfo struct {
 	name    string
 	filler  fillerFunc
 	remover removerFunc
+
+	// isOptional is true, if no padding is added to data that is a multiple of the block size.
+	// Such a padding is ambiguous, as the remover can not know whether the last block has been padded,
+	// unless there is a prefix block that contains the length of the data.
+	isOptional bool
+
+	// hasPrefix is true, if the padding prepends a prefix block to the data.
+	hasPrefix bool
 }
@@ -0,0 +1,205 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package radius implements the hiding of the User-Password attribute of RADIUS (RFC 2865, section 5.2).
+//
+// The password is padded with zero bytes to a multiple of 16 bytes.
+// Then each 16 byte block is XORed with the MD5 hash of the shared secret
+// and the previous hidden block. The first block uses the Request Authenticator instead.
+//
+// The padding never adds a full block, so a password may not contain zero bytes.
+// An empty password is hidden as one block.
+package radius
+
+import (
+	"crypto/md5"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+)
+
+// ******** Public constants ********
+
+const (
+	// AuthenticatorSize is the size of the Request Authenticator.
+	AuthenticatorSize = 16
+
+	// MaxPasswordLen is the maximum length of a hidden password.
+	MaxPasswordLen = 128
+)
+
+// ******** Private constants ********
+
+// blockSize is the size of the blocks of the hidden password. This is the size of an MD5 hash.
+const blockSize = md5.Size
+
+// ******** Public errors ********
+
+var (
+	// ErrInvalidAuthenticator means that the Request Authenticator does not have a length of 16 bytes.
+	ErrInvalidAuthenticator = errors.New(`invalid request authenticator`)
+
+	// ErrInvalidSecret means that the shared secret is empty.
+	ErrInvalidSecret = errors.New(`invalid shared secret`)
+
+	// ErrPasswordTooLong means that the padded password is longer than 128 bytes.
+	ErrPasswordTooLong = errors.New(`password too long`)
+
+	// ErrInvalidPassword means that the password contains a zero byte.
+	ErrInvalidPassword = errors.New(`invalid password`)
+
+	// ErrInvalidHiddenPassword means that the hidden password does not have a valid length
+	// or that the recovered password is not correctly padded.
+	ErrInvalidHiddenPassword = errors.New(`invalid hidden password`)
+)
+
+// ******** Public functions ********
+
+// HidePassword hides a password with the shared secret and the Request Authenticator
+// of an Access-Request. It returns a new slice with a length that is a multiple of 16.
+func HidePassword(password []byte, secret []byte, authenticator []byte) ([]byte, error) {
+	err := checkParameters(secret, authenticator)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(password) > MaxPasswordLen {
+		return nil, ErrPasswordTooLong
+	}
+
+	if containsZero(password) {
+		return nil, ErrInvalidPassword
+	}
+
+	// 1. Pad the password. An empty password is padded to one block.
+	padder := newPadder()
+	var result []byte
+	if len(password) != 0 {
+		result = padder.Pad(password)
+	} else {
+		result = make([]byte, blockSize)
+	}
+
+	// 2. Hide the password. Each block is chained to the previous hidden block.
+	chain := authenticator
+	for i := 0; i < len(result); i += blockSize {
+		block := result[i : i+blockSize]
+		xorHash(block, secret, chain)
+		chain = block
+	}
+
+	return result, nil
+}
+
+// RecoverPassword recovers a password that has been hidden with the shared secret
+// and the Request Authenticator of an Access-Request. It returns a new slice.
+//
+// The hidden password must have a length that is a multiple of 16 and at most 128.
+// The recovered password must be padded with at most 15 zero bytes and
+// must not contain any other zero bytes.
+// A wrong secret is not detected reliably, as there is no integrity protection.
+func RecoverPassword(hidden []byte, secret []byte, authenticator []byte) ([]byte, error) {
+	err := checkParameters(secret, authenticator)
+	if err != nil {
+		return nil, err
+	}
+
+	hiddenLen := len(hidden)
+	if hiddenLen == 0 || hiddenLen > MaxPasswordLen || hiddenLen%blockSize != 0 {
+		return nil, ErrInvalidHiddenPassword
+	}
+
+	// 1. Recover the padded password. Each block is chained to the previous hidden block.
+	padded := make([]byte, hiddenLen)
+	copy(padded, hidden)
+	chain := authenticator
+	for i := 0; i < hiddenLen; i += blockSize {
+		xorHash(padded[i:i+blockSize], secret, chain)
+		chain = hidden[i : i+blockSize]
+	}
+
+	// 2. An empty password is padded to one block of zero bytes.
+	if hiddenLen == blockSize && !containsNonZero(padded) {
+		return padded[:0], nil
+	}
+
+	// 3. Remove the padding. There must not be any zero bytes left.
+	result, _ := newPadder().Unpad(padded)
+	if containsZero(result) {
+		return nil, ErrInvalidHiddenPassword
+	}
+
+	return result, nil
+}
+
+// ******** Private functions ********
+
+// checkParameters checks the shared secret and the Request Authenticator.
+func checkParameters(secret []byte, authenticator []byte) error {
+	if len(secret) == 0 {
+		return ErrInvalidSecret
+	}
+
+	if len(authenticator) != AuthenticatorSize {
+		return ErrInvalidAuthenticator
+	}
+
+	return nil
+}
+
+// newPadder creates the padder for the padding of passwords.
+func newPadder() *blockpad.BlockPad {
+	// This can not fail, as the pad algorithm and the block size are valid.
+	padder, _ := blockpad.NewBlockPadding(blockpad.McryptZero, blockSize)
+	return padder
+}
+
+// xorHash XORs a block with the MD5 hash of the shared secret and the chain value.
+func xorHash(block []byte, secret []byte, chain []byte) {
+	h := md5.New()
+	h.Write(secret)
+	h.Write(chain)
+	b := h.Sum(make([]byte, 0, blockSize))
+
+	for i := range block {
+		block[i] ^= b[i]
+	}
+}
+
+// containsZero checks if data contains a zero byte.
+// It always scans *all* data to thwart timing attacks.
+func containsZero(data []byte) bool {
+	result := false
+	for _, b := range data {
+		result = result || b == 0
+	}
+
+	return result
+}
+
+// containsNonZero checks if data contains a byte that is not zero.
+// It always scans *all* data to thwart timing attacks.
+func containsNonZero(data []byte) bool {
+	var acc byte
+	for _, b := range data {
+		acc |= b
+	}
+
+	return acc != 0
+}
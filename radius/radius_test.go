@@ -0,0 +1,189 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// These are the values of the example in RFC 2865, section 7.1.
+const (
+	rfcSecret        = `xyzzy5461`
+	rfcAuthenticator = `0f403f9473978057bd83d5cb98f4227a`
+	rfcPassword      = `arctangent`
+	rfcHidden        = `0dbe708d93d413ce3196e43f782a0aee`
+)
+
+// ******** Functional tests ********
+
+func TestRFC2865Example(t *testing.T) {
+	authenticator, _ := hex.DecodeString(rfcAuthenticator)
+	expected, _ := hex.DecodeString(rfcHidden)
+
+	hidden, err := HidePassword([]byte(rfcPassword), []byte(rfcSecret), authenticator)
+	if err != nil {
+		t.Fatalf(`HidePassword failed: %v`, err)
+	}
+	if !bytes.Equal(hidden, expected) {
+		t.Fatalf(`Hidden password is %x instead of %x`, hidden, expected)
+	}
+
+	var password []byte
+	password, err = RecoverPassword(hidden, []byte(rfcSecret), authenticator)
+	if err != nil {
+		t.Fatalf(`RecoverPassword failed: %v`, err)
+	}
+	if string(password) != rfcPassword {
+		t.Fatalf(`Recovered password is '%s' instead of '%s'`, password, rfcPassword)
+	}
+}
+
+func TestMultipleBlocks(t *testing.T) {
+	// The expected value has been calculated independently with Python's hashlib.
+	authenticator, _ := hex.DecodeString(rfcAuthenticator)
+	expected, _ := hex.DecodeString(`0dec639881c903c42d86c44b104b7ececa4ed1e2a323a13f74facb2bd98b5aeb09f7d1f5b2dff0d3e20bc99e4438159d`)
+	password := []byte(`a password that is longer than thirty-two bytes`)
+
+	hidden, err := HidePassword(password, []byte(rfcSecret), authenticator)
+	if err != nil {
+		t.Fatalf(`HidePassword failed: %v`, err)
+	}
+	if !bytes.Equal(hidden, expected) {
+		t.Fatalf(`Hidden password is %x instead of %x`, hidden, expected)
+	}
+}
+
+func TestHideRecover(t *testing.T) {
+	secret := []byte(`shared secret`)
+	authenticator := []byte(`0123456789abcdef`)
+
+	for passwordLen := 0; passwordLen <= MaxPasswordLen; passwordLen++ {
+		password := makePassword(passwordLen)
+		saved := bytes.Clone(password)
+
+		hidden, err := HidePassword(password, secret, authenticator)
+		if err != nil {
+			t.Fatalf(`HidePassword failed for length %d: %v`, passwordLen, err)
+		}
+		if !bytes.Equal(password, saved) {
+			t.Fatalf(`HidePassword modified the password with length %d`, passwordLen)
+		}
+
+		expectedLen := max(blockSize, (passwordLen+blockSize-1)/blockSize*blockSize)
+		if len(hidden) != expectedLen {
+			t.Fatalf(`Hidden password for length %d has length %d instead of %d`, passwordLen, len(hidden), expectedLen)
+		}
+
+		var recovered []byte
+		recovered, err = RecoverPassword(hidden, secret, authenticator)
+		if err != nil {
+			t.Fatalf(`RecoverPassword failed for length %d: %v`, passwordLen, err)
+		}
+		if !bytes.Equal(recovered, password) {
+			t.Fatalf(`Recovered password differs from password with length %d`, passwordLen)
+		}
+	}
+}
+
+// ******** Test invalid data ********
+
+func TestHideInvalid(t *testing.T) {
+	secret := []byte(rfcSecret)
+	authenticator, _ := hex.DecodeString(rfcAuthenticator)
+
+	_, err := HidePassword(makePassword(MaxPasswordLen+1), secret, authenticator)
+	if !errors.Is(err, ErrPasswordTooLong) {
+		t.Fatalf(`Wrong error for too long password: %v`, err)
+	}
+
+	_, err = HidePassword([]byte("pass\x00word"), secret, authenticator)
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf(`Wrong error for password with zero byte: %v`, err)
+	}
+
+	_, err = HidePassword([]byte(rfcPassword), nil, authenticator)
+	if !errors.Is(err, ErrInvalidSecret) {
+		t.Fatalf(`Wrong error for empty secret: %v`, err)
+	}
+
+	_, err = HidePassword([]byte(rfcPassword), secret, authenticator[1:])
+	if !errors.Is(err, ErrInvalidAuthenticator) {
+		t.Fatalf(`Wrong error for short authenticator: %v`, err)
+	}
+}
+
+func TestRecoverInvalidLength(t *testing.T) {
+	secret := []byte(rfcSecret)
+	authenticator, _ := hex.DecodeString(rfcAuthenticator)
+
+	for _, hiddenLen := range []int{0, 1, blockSize - 1, blockSize + 1, MaxPasswordLen + blockSize} {
+		_, err := RecoverPassword(make([]byte, hiddenLen), secret, authenticator)
+		if !errors.Is(err, ErrInvalidHiddenPassword) {
+			t.Fatalf(`Wrong error for hidden password with length %d: %v`, hiddenLen, err)
+		}
+	}
+}
+
+func TestRecoverInvalidPadding(t *testing.T) {
+	secret := []byte(rfcSecret)
+	authenticator, _ := hex.DecodeString(rfcAuthenticator)
+
+	// A full block of padding and a zero byte inside the password are not allowed.
+	for _, padded := range [][]byte{
+		append(makePassword(blockSize), make([]byte, blockSize)...),
+		append([]byte("pass\x00word"), make([]byte, blockSize-9)...),
+	} {
+		hidden := hideRaw(padded, secret, authenticator)
+		_, err := RecoverPassword(hidden, secret, authenticator)
+		if !errors.Is(err, ErrInvalidHiddenPassword) {
+			t.Fatalf(`Wrong error for invalid padding %x: %v`, padded, err)
+		}
+	}
+}
+
+// ******** Private functions ********
+
+// makePassword creates a password of a given length without zero bytes.
+func makePassword(len int) []byte {
+	result := make([]byte, len)
+	for i := range result {
+		result[i] = byte('a' + i%26)
+	}
+
+	return result
+}
+
+// hideRaw hides already padded data without any checks.
+func hideRaw(padded []byte, secret []byte, authenticator []byte) []byte {
+	result := bytes.Clone(padded)
+	chain := authenticator
+	for i := 0; i < len(result); i += blockSize {
+		xorHash(result[i:i+blockSize], secret, chain)
+		chain = result[i : i+blockSize]
+	}
+
+	return result
+}
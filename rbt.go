@@ -0,0 +1,163 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"github.com/xformerfhs/blockpad/internal/padlen"
+	"github.com/xformerfhs/blockpad/internal/slicehelper"
+)
+
+// ******** This file contains the residual block termination ********
+
+// ******** Public types ********
+
+// ResidualBlockTerminator encrypts and decrypts data without padding with residual block termination.
+//
+// The full blocks are encrypted in CBC mode. The final partial block, the residual block,
+// is XORed with the encryption of the last ciphertext block, or of the initialization vector,
+// if there are no full blocks. So the ciphertext has the same length as the plaintext.
+//
+// As the residual block is encrypted like a stream cipher with a deterministic key stream,
+// it is vulnerable to manipulation and leaks the XOR of residual blocks with the same preceding ciphertext.
+//
+// A ResidualBlockTerminator is safe for concurrent use by multiple goroutines, if the block cipher is.
+type ResidualBlockTerminator struct {
+	block     cipher.Block
+	blockSize int
+}
+
+// ******** Public creation function ********
+
+// NewResidualBlockTerminator creates a residual block terminator for a block cipher.
+func NewResidualBlockTerminator(block cipher.Block) (*ResidualBlockTerminator, error) {
+	blockSize := block.BlockSize()
+	err := checkBlockSize(blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResidualBlockTerminator{block: block, blockSize: blockSize}, nil
+}
+
+// ******** Public functions ********
+
+// SplitLastBlock splits a byte slice into the data up to the last full block and the residual data.
+// It is the counterpart of PadLastBlock. Nothing is copied.
+// The residual data is shorter than a block and is empty, if the data is a multiple of the block size.
+func (rt *ResidualBlockTerminator) SplitLastBlock(data []byte) ([]byte, []byte) {
+	fullBlockDataLen, _, _ := padlen.Lengths(len(data), rt.blockSize)
+
+	return data[:fullBlockDataLen], data[fullBlockDataLen:]
+}
+
+// TerminateLastBlock encrypts or decrypts the residual data and appends the result to dst.
+// It returns the updated slice.
+// lastCipherBlock is the last full ciphertext block, or the initialization vector, if there are no full blocks.
+// On decryption it has to be saved before the full blocks are decrypted in place.
+// Encryption and decryption are the same operation.
+func (rt *ResidualBlockTerminator) TerminateLastBlock(dst []byte, residual []byte, lastCipherBlock []byte) []byte {
+	if len(residual) >= rt.blockSize {
+		panic(`blockpad: residual data is not shorter than a block`)
+	}
+
+	if len(lastCipherBlock) != rt.blockSize {
+		panic(`blockpad: last cipher block does not have the block size`)
+	}
+
+	result, out := slicehelper.ForAppend(dst, len(residual))
+	rt.terminate(out, residual, lastCipherBlock)
+
+	return result
+}
+
+// Encrypt encrypts plaintext with the initialization vector and appends the result to dst.
+// It returns the updated slice. The ciphertext has the same length as the plaintext.
+// To reuse plaintext's storage for the encrypted output, use plaintext[:0] as dst.
+// Otherwise, the remaining capacity of dst must not overlap plaintext.
+func (rt *ResidualBlockTerminator) Encrypt(dst []byte, plaintext []byte, iv []byte) ([]byte, error) {
+	if len(iv) != rt.blockSize {
+		return nil, ErrInvalidIV
+	}
+
+	fullBlockData, residual := rt.SplitLastBlock(plaintext)
+	fullBlockDataLen := len(fullBlockData)
+
+	// 1. Encrypt the full blocks in CBC mode directly into the destination.
+	result, out := slicehelper.ForAppend(dst, len(plaintext))
+	cipher.NewCBCEncrypter(rt.block, iv).CryptBlocks(out[:fullBlockDataLen], fullBlockData)
+
+	// 2. Encrypt the residual block with the last ciphertext block.
+	rt.terminate(out[fullBlockDataLen:], residual, rt.lastCipherBlock(out[:fullBlockDataLen], iv))
+
+	return result, nil
+}
+
+// Decrypt decrypts ciphertext with the initialization vector and appends the result to dst.
+// It returns the updated slice. The plaintext has the same length as the ciphertext.
+// To reuse ciphertext's storage for the decrypted output, use ciphertext[:0] as dst.
+// Otherwise, the remaining capacity of dst must not overlap ciphertext.
+func (rt *ResidualBlockTerminator) Decrypt(dst []byte, ciphertext []byte, iv []byte) ([]byte, error) {
+	if len(iv) != rt.blockSize {
+		return nil, ErrInvalidIV
+	}
+
+	fullBlockData, residual := rt.SplitLastBlock(ciphertext)
+	fullBlockDataLen := len(fullBlockData)
+
+	// 1. Save the last ciphertext block, as it may be overwritten by the decryption.
+	lastCipherBlock := append([]byte(nil), rt.lastCipherBlock(fullBlockData, iv)...)
+
+	// 2. Decrypt the full blocks in CBC mode directly into the destination.
+	result, out := slicehelper.ForAppend(dst, len(ciphertext))
+	cipher.NewCBCDecrypter(rt.block, iv).CryptBlocks(out[:fullBlockDataLen], fullBlockData)
+
+	// 3. Decrypt the residual block with the last ciphertext block.
+	rt.terminate(out[fullBlockDataLen:], residual, lastCipherBlock)
+
+	return result, nil
+}
+
+// BlockSize returns the block size of the block cipher.
+func (rt *ResidualBlockTerminator) BlockSize() int {
+	return rt.blockSize
+}
+
+// ******** Private functions ********
+
+// lastCipherBlock returns the last block of the encrypted full blocks
+// or the initialization vector, if there are no full blocks.
+func (rt *ResidualBlockTerminator) lastCipherBlock(fullBlockData []byte, iv []byte) []byte {
+	fullBlockDataLen := len(fullBlockData)
+	if fullBlockDataLen == 0 {
+		return iv
+	}
+
+	return fullBlockData[fullBlockDataLen-rt.blockSize:]
+}
+
+// terminate XORs the residual data with the encryption of the last cipher block into out.
+func (rt *ResidualBlockTerminator) terminate(out []byte, residual []byte, lastCipherBlock []byte) {
+	keyStream := make([]byte, rt.blockSize)
+	rt.block.Encrypt(keyStream, lastCipherBlock)
+	subtle.XORBytes(out, residual, keyStream[:len(residual)])
+}
@@ -0,0 +1,161 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// These are the key and the initialization vector of the residual block termination test vectors.
+const (
+	rbtKey = `000102030405060708090a0b0c0d0e0f`
+	rbtIV  = `f0e0d0c0b0a090807060504030201000`
+)
+
+// ******** Functional tests ********
+
+func TestResidualBlockTerminatorVector(t *testing.T) {
+	// The expected values have been calculated independently with the CBC and ECB modes of OpenSSL.
+	terminator, iv := makeResidualBlockTerminator(t, rbtKey, rbtIV)
+
+	for _, tv := range []struct {
+		plaintext  string
+		ciphertext string
+	}{
+		{`Residual block termination keeps the length`, `1ce3a9dab19b17da782693be44108711fc7a27eeaaf98c847ec696193c8a205bc1c8c3061667a3185fd636`},
+		{`Short text`, `246a93e905e64943da84`},
+	} {
+		ciphertext, err := terminator.Encrypt(nil, []byte(tv.plaintext), iv)
+		if err != nil {
+			t.Fatalf(`Encrypt failed: %v`, err)
+		}
+		if hex.EncodeToString(ciphertext) != tv.ciphertext {
+			t.Fatalf(`Ciphertext is %x instead of %s`, ciphertext, tv.ciphertext)
+		}
+
+		var decrypted []byte
+		decrypted, err = terminator.Decrypt(nil, ciphertext, iv)
+		if err != nil {
+			t.Fatalf(`Decrypt failed: %v`, err)
+		}
+		if string(decrypted) != tv.plaintext {
+			t.Fatalf(`Decrypted data is '%s' instead of '%s'`, decrypted, tv.plaintext)
+		}
+	}
+}
+
+func TestResidualBlockTerminatorAll(t *testing.T) {
+	terminator, iv := makeResidualBlockTerminator(t, rbtKey, rbtIV)
+
+	for dataLen := 0; dataLen <= 5*aes.BlockSize; dataLen++ {
+		plaintext := makeTestSlice(dataLen)
+
+		ciphertext, err := terminator.Encrypt(nil, plaintext, iv)
+		if err != nil {
+			t.Fatalf(`Encrypt failed for length %d: %v`, dataLen, err)
+		}
+		if len(ciphertext) != dataLen {
+			t.Fatalf(`Ciphertext length is %d instead of %d`, len(ciphertext), dataLen)
+		}
+
+		// Full blocks are encrypted in CBC mode.
+		fullBlockData, _ := terminator.SplitLastBlock(plaintext)
+		expected := make([]byte, len(fullBlockData))
+		cipher.NewCBCEncrypter(terminator.block, iv).CryptBlocks(expected, fullBlockData)
+		if !bytes.Equal(ciphertext[:len(expected)], expected) {
+			t.Fatalf(`Full blocks are not encrypted in CBC mode for length %d`, dataLen)
+		}
+
+		// Decrypt in place.
+		var decrypted []byte
+		decrypted, err = terminator.Decrypt(ciphertext[:0], ciphertext, iv)
+		if err != nil {
+			t.Fatalf(`Decrypt failed for length %d: %v`, dataLen, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf(`Decrypted data differs for length %d`, dataLen)
+		}
+	}
+}
+
+func TestResidualBlockTerminatorSplit(t *testing.T) {
+	terminator, iv := makeResidualBlockTerminator(t, rbtKey, rbtIV)
+	plaintext := makeTestSlice(2*aes.BlockSize + 5)
+
+	// Encrypt with the split functions and a block mode of the caller.
+	fullBlockData, residual := terminator.SplitLastBlock(plaintext)
+	if len(fullBlockData) != 2*aes.BlockSize || len(residual) != 5 {
+		t.Fatalf(`Wrong split lengths %d and %d`, len(fullBlockData), len(residual))
+	}
+
+	ciphertext := make([]byte, len(fullBlockData))
+	cipher.NewCBCEncrypter(terminator.block, iv).CryptBlocks(ciphertext, fullBlockData)
+	ciphertext = terminator.TerminateLastBlock(ciphertext, residual, ciphertext[aes.BlockSize:])
+
+	expected, _ := terminator.Encrypt(nil, plaintext, iv)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Fatalf(`Split encryption differs from Encrypt`)
+	}
+}
+
+// ******** Test invalid data ********
+
+func TestResidualBlockTerminatorInvalidIV(t *testing.T) {
+	terminator, iv := makeResidualBlockTerminator(t, rbtKey, rbtIV)
+
+	_, err := terminator.Encrypt(nil, makeTestSlice(20), iv[1:])
+	if !errors.Is(err, ErrInvalidIV) {
+		t.Fatalf(`Wrong error encrypting with short initialization vector: %v`, err)
+	}
+
+	_, err = terminator.Decrypt(nil, makeTestSlice(20), nil)
+	if !errors.Is(err, ErrInvalidIV) {
+		t.Fatalf(`Wrong error decrypting without initialization vector: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// makeResidualBlockTerminator creates a residual block terminator with AES and
+// returns it together with the initialization vector.
+func makeResidualBlockTerminator(t *testing.T, hexKey string, hexIV string) (*ResidualBlockTerminator, []byte) {
+	key, _ := hex.DecodeString(hexKey)
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	var terminator *ResidualBlockTerminator
+	terminator, err = NewResidualBlockTerminator(aesCipher)
+	if err != nil {
+		t.Fatalf(`Could not create residual block terminator: %v`, err)
+	}
+
+	iv, _ := hex.DecodeString(hexIV)
+	return terminator, iv
+}
@@ -0,0 +1,200 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+import (
+	"crypto/cipher"
+	"github.com/xformerfhs/blockpad/internal/padlen"
+	"io"
+)
+
+// ******** This file contains the streaming unpadding reader ********
+
+// ******** Public types ********
+
+// Reader is an [io.Reader] that unpads the data read from an underlying reader.
+// It always holds back the last full block until the underlying reader signals [io.EOF].
+// Then the padding is removed from this block and only the real data is returned.
+//
+// If the Reader has been created with a block mode, all blocks are decrypted
+// with this block mode before they are unpadded.
+//
+// If the data read is not a multiple of the block size, ErrInvalidPaddedDataLen is returned.
+// If the padding is invalid, ErrInvalidPadding is returned.
+// Both errors can only be returned at the end of the data.
+//
+// If the pad algorithm prepends a prefix block, it is removed from the data
+// and checked at the end of the data.
+//
+// A Reader is not safe for concurrent use by multiple goroutines.
+type Reader struct {
+	padder *BlockPad
+	source io.Reader
+	mode   cipher.BlockMode
+	buffer []byte
+	start  int
+	ready  int
+	end    int
+	err    error
+
+	// prefixBlock holds the prefix block, once it has been read, and
+	// releasedLen is the length of the data released after the prefix block.
+	prefixBlock []byte
+	releasedLen int
+}
+
+// ******** Public creation functions ********
+
+// NewReader creates a Reader that unpads the data read from r.
+func (pb *BlockPad) NewReader(r io.Reader) *Reader {
+	return &Reader{
+		padder: pb,
+		source: r,
+		buffer: make([]byte, (streamChunkBlockCount+1)*pb.blockSize),
+	}
+}
+
+// NewCryptReader creates a Reader that unpads the data read from r
+// after it has been processed by the block mode, e.g. a CBC decrypter.
+// The block size of the block mode must be the same as the block size of the padder.
+func (pb *BlockPad) NewCryptReader(r io.Reader, mode cipher.BlockMode) (*Reader, error) {
+	if mode.BlockSize() != pb.blockSize {
+		return nil, ErrInvalidBlockMode
+	}
+
+	result := pb.NewReader(r)
+	result.mode = mode
+
+	return result, nil
+}
+
+// ******** Public functions ********
+
+// Read reads unpadded data into p.
+// It implements the [io.Reader] interface.
+func (pr *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for pr.start == pr.ready {
+		if pr.err != nil {
+			return 0, pr.err
+		}
+
+		pr.fill()
+	}
+
+	n := copy(p, pr.buffer[pr.start:pr.ready])
+	pr.start += n
+
+	return n, nil
+}
+
+// ******** Private functions ********
+
+// fill reads data from the underlying reader and releases all blocks that are known not to be the last block.
+// The buffer layout is as follows:
+//
+//   - buffer[start:ready] contains processed data that has not yet been returned.
+//   - buffer[ready:end] contains data from the underlying reader that has not yet been processed.
+func (pr *Reader) fill() {
+	// 1. Move unprocessed data to the front of the buffer.
+	// There are never more than blockSize unprocessed bytes, so there is always room for at least one chunk.
+	pr.end = copy(pr.buffer, pr.buffer[pr.ready:pr.end])
+	pr.start = 0
+	pr.ready = 0
+
+	// 2. Read more data.
+	n, err := pr.source.Read(pr.buffer[pr.end:])
+	pr.end += n
+
+	if err == io.EOF {
+		pr.finish()
+		return
+	}
+
+	// 3. Release all full blocks that are followed by at least one more byte.
+	if pr.end > 0 {
+		blockSize := pr.padder.blockSize
+		releaseLen, _, _ := padlen.Lengths(pr.end-1, blockSize)
+		pr.cryptBlocks(pr.buffer[:releaseLen])
+		pr.ready = releaseLen
+		pr.takePrefixBlock()
+		pr.releasedLen += pr.ready - pr.start
+	}
+
+	pr.err = err
+}
+
+// finish processes the remaining data when the underlying reader has no more data.
+func (pr *Reader) finish() {
+	blockSize := pr.padder.blockSize
+	dataLen := pr.end
+
+	// Padded data always consists of at least one full block, unless the padding is optional and has no prefix block.
+	if dataLen == 0 && pr.padder.worker.isOptional && !pr.padder.worker.hasPrefix {
+		pr.err = io.EOF
+		return
+	}
+
+	if dataLen == 0 || dataLen%blockSize != 0 {
+		pr.err = ErrInvalidPaddedDataLen
+		return
+	}
+
+	pr.cryptBlocks(pr.buffer[:dataLen])
+
+	// The prefix block may still be in the buffer.
+	pr.ready = dataLen
+	pr.takePrefixBlock()
+
+	lastBlockIndex := max(pr.start, dataLen-blockSize)
+	paddedDataLen := pr.releasedLen + dataLen - pr.start
+	unpaddedData, err := pr.padder.worker.remover(pr.prefixBlock, pr.buffer[lastBlockIndex:dataLen], dataLen-lastBlockIndex, paddedDataLen, blockSize)
+	if err != nil {
+		pr.ready = lastBlockIndex
+		pr.err = err
+		return
+	}
+
+	pr.ready = lastBlockIndex + len(unpaddedData)
+	pr.err = io.EOF
+}
+
+// takePrefixBlock removes the prefix block from the processed data, if the pad algorithm has one
+// and it has not been removed, yet.
+func (pr *Reader) takePrefixBlock() {
+	blockSize := pr.padder.blockSize
+	if !pr.padder.worker.hasPrefix || pr.prefixBlock != nil || pr.ready < blockSize {
+		return
+	}
+
+	pr.prefixBlock = append([]byte(nil), pr.buffer[:blockSize]...)
+	pr.start = blockSize
+}
+
+// cryptBlocks processes blocks in place with the block mode, if there is one.
+func (pr *Reader) cryptBlocks(blocks []byte) {
+	if pr.mode != nil && len(blocks) > 0 {
+		pr.mode.CryptBlocks(blocks, blocks)
+	}
+}
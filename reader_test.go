@@ -0,0 +1,220 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	mrand "math/rand"
+	"testing"
+	"testing/iotest"
+)
+
+// ******** Functional tests ********
+
+func TestReaderAll(t *testing.T) {
+	for padType := Zero; padType <= maxAlgorithm; padType++ {
+		padder, err := NewBlockPadding(padType, testBlockSize)
+		if err != nil {
+			t.Fatalf(`Error creating BlockPad with pad type %d: %v`, padType, err)
+		}
+
+		for i := 0; i < loopCount; i++ {
+			_, data := makeZeroSafeRandomLenTestSlice(padType)
+			paddedData := padder.Pad(data)
+
+			reader := padder.NewReader(iotest.HalfReader(bytes.NewReader(paddedData)))
+
+			var unpaddedData []byte
+			unpaddedData, err = io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf(`%s: reading padded data failed: %v`, padder.String(), err)
+			}
+			if !bytes.Equal(unpaddedData, data) {
+				t.Fatalf("%s: unpaddedData != data:\n        data=%02x\nunpaddedData=%02x",
+					padder.String(),
+					data, unpaddedData)
+			}
+		}
+	}
+}
+
+func TestReaderDataErrEOF(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	for dataLen := 0; dataLen <= 3*testBlockSize; dataLen++ {
+		data := makeTestSlice(dataLen)
+
+		reader := padder.NewReader(iotest.DataErrReader(bytes.NewReader(padder.Pad(data))))
+
+		var unpaddedData []byte
+		unpaddedData, err = io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf(`%s: reading padded data failed (dataLen=%d): %v`, padder.String(), dataLen, err)
+		}
+		if !bytes.Equal(unpaddedData, data) {
+			t.Fatalf(`%s: unpaddedData != data (dataLen=%d)`, padder.String(), dataLen)
+		}
+	}
+}
+
+func TestCryptReader(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	key := makeTestSlice(32)
+	iv := makeTestSlice(aes.BlockSize)
+
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	for i := 0; i < loopCount; i++ {
+		// Use data larger than a chunk to test the chunking.
+		data := makeTestSlice(mrand.Intn(streamChunkBlockCount*aes.BlockSize*3) + 1)
+
+		encryptedData := padder.Pad(data)
+		cipher.NewCBCEncrypter(aesCipher, iv).CryptBlocks(encryptedData, encryptedData)
+
+		var reader *Reader
+		reader, err = padder.NewCryptReader(bytes.NewReader(encryptedData), cipher.NewCBCDecrypter(aesCipher, iv))
+		if err != nil {
+			t.Fatalf(`Could not create crypt reader: %v`, err)
+		}
+
+		var decryptedData []byte
+		decryptedData, err = io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf(`Reading encrypted data failed (dataLen=%d): %v`, len(data), err)
+		}
+		if !bytes.Equal(decryptedData, data) {
+			t.Fatalf(`Decrypted data differs from clear data (dataLen=%d)`, len(data))
+		}
+	}
+}
+
+// ******** Test invalid paddings ********
+
+func TestReaderInvalidPadding(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	data := makeTestSlice(5 * testBlockSize)
+	data[len(data)-1] = 0x5a
+
+	reader := padder.NewReader(bytes.NewReader(data))
+
+	var unpaddedData []byte
+	unpaddedData, err = io.ReadAll(reader)
+	if !errors.Is(err, ErrInvalidPadding) {
+		t.Fatalf(`%s: wrong error reading invalid padded data: %v`, padder.String(), err)
+	}
+
+	// All blocks before the last one are returned before the error occurs.
+	if !bytes.Equal(unpaddedData, data[:len(data)-testBlockSize]) {
+		t.Fatalf(`%s: wrong data returned before invalid padding`, padder.String())
+	}
+}
+
+func TestReaderOptionalPaddingEmpty(t *testing.T) {
+	padder, err := NewBlockPadding(McryptZero, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, McryptZero, err)
+	}
+
+	reader := padder.NewReader(bytes.NewReader(nil))
+
+	var unpaddedData []byte
+	unpaddedData, err = io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf(`%s: reading empty data failed: %v`, padder.String(), err)
+	}
+	if len(unpaddedData) != 0 {
+		t.Fatalf(`%s: reading empty data returned %d bytes`, padder.String(), len(unpaddedData))
+	}
+}
+
+func TestReaderPrefixBlock(t *testing.T) {
+	padder, err := NewBlockPadding(ISO97971M3, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, ISO97971M3, err)
+	}
+
+	for dataLen := 0; dataLen <= 3*testBlockSize; dataLen++ {
+		data := makeTestSlice(dataLen)
+		paddedData := padder.Pad(data)
+
+		reader := padder.NewReader(iotest.OneByteReader(bytes.NewReader(paddedData)))
+
+		var unpaddedData []byte
+		unpaddedData, err = io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf(`%s: reading padded data failed (dataLen=%d): %v`, padder.String(), dataLen, err)
+		}
+		if !bytes.Equal(unpaddedData, data) {
+			t.Fatalf(`%s: unpaddedData != data (dataLen=%d)`, padder.String(), dataLen)
+		}
+
+		// The length in the prefix block is checked at the end of the data.
+		binary.BigEndian.PutUint16(paddedData[testBlockSize-2:], uint16(dataLen+testBlockSize)<<3)
+		reader = padder.NewReader(bytes.NewReader(paddedData))
+
+		_, err = io.ReadAll(reader)
+		if !errors.Is(err, ErrInvalidPadding) {
+			t.Fatalf(`%s: wrong error reading data with wrong length (dataLen=%d): %v`, padder.String(), dataLen, err)
+		}
+	}
+
+	// There has to be a prefix block.
+	reader := padder.NewReader(bytes.NewReader(nil))
+	_, err = io.ReadAll(reader)
+	if !errors.Is(err, ErrInvalidPaddedDataLen) {
+		t.Fatalf(`%s: wrong error reading empty data: %v`, padder.String(), err)
+	}
+}
+
+func TestReaderWrongSize(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	for _, dataLen := range []int{0, 1, testBlockSize - 1, testBlockSize + 1, (testBlockSize << 1) - 3} {
+		reader := padder.NewReader(bytes.NewReader(makeTestSlice(dataLen)))
+
+		_, err = io.ReadAll(reader)
+		if !errors.Is(err, ErrInvalidPaddedDataLen) {
+			t.Fatalf(`%s: wrong error reading padded data of wrong size %d: %v`, padder.String(), dataLen, err)
+		}
+	}
+}
@@ -24,7 +24,7 @@ package blockpad
 
 // zeroRemover removes zero padding (ISO 10118-1 and ISO 9797-1).
 // It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle!
-func zeroRemover(data []byte, dataLen int, blockSize int) ([]byte, error) {
+func zeroRemover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
 	lastIndex := dataLen - 1
 	if data[lastIndex] != 0 {
 		return nil, ErrInvalidPadding
@@ -45,7 +45,7 @@ func zeroRemover(data []byte, dataLen int, blockSize int) ([]byte, error) {
 
 // pkcs7Remover removes PKCS#7 padding (RFC 5652).
 // It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle!
-func pkcs7Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
+func pkcs7Remover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
 	firstIndex, lastIndex, firstPadIndex, padLenByte, _, err := checkLengthByte(data, dataLen, blockSize)
 	if err != nil {
 		return nil, err
@@ -65,7 +65,7 @@ func pkcs7Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
 
 // x923Remover removes ANSI X.923 padding.
 // It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle!
-func x923Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
+func x923Remover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
 	firstIndex, lastIndex, firstPadIndex, _, _, err := checkLengthByte(data, dataLen, blockSize)
 	if err != nil {
 		return nil, err
@@ -86,7 +86,7 @@ func x923Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
 // iso10126Remover removes ISO 10126 padding.
 // It is the fastest to unpad and always takes constant time.
 // It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle!
-func iso10126Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
+func iso10126Remover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
 	_, _, _, _, padLen, err := checkLengthByte(data, dataLen, blockSize)
 	if err != nil {
 		return nil, err
@@ -101,7 +101,7 @@ func iso10126Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
 
 // rfc4303Remover removes RFC 4303 padding (IPSec).
 // It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle!
-func rfc4303Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
+func rfc4303Remover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
 	firstIndex, lastIndex, firstPadIndex, padLenByte, _, err := checkLengthByte(data, dataLen, blockSize)
 	if err != nil {
 		return nil, err
@@ -123,7 +123,7 @@ func rfc4303Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
 
 // iso78164Remover removes ISO 7816-4 padding (Smart cards).
 // It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle!
-func iso78164Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
+func iso78164Remover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
 	lastIndex := dataLen - 1
 	firstIndex := dataLen - blockSize
 	isValid := true
@@ -159,7 +159,7 @@ func iso78164Remover(data []byte, dataLen int, blockSize int) ([]byte, error) {
 
 // arbitraryTailBytePaddingRemover removes arbitrary tail byte padding.
 // It never returns an error and is therefore not susceptible to a padding oracle!
-func arbitraryTailBytePaddingRemover(data []byte, dataLen int, blockSize int) ([]byte, error) {
+func arbitraryTailBytePaddingRemover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
 	firstIndex := dataLen - blockSize
 	lastIndex := dataLen - 1
 	padByte := data[lastIndex]
@@ -175,6 +175,71 @@ func arbitraryTailBytePaddingRemover(data []byte, dataLen int, blockSize int) ([
 	return data[:firstPadIndex], nil
 }
 
+// mcryptZeroRemover removes the zero padding of PHP mcrypt.
+// As this padding never adds a full block, at most blockSize - 1 trailing zero bytes are removed.
+// It never returns an error and is therefore not susceptible to a padding oracle!
+func mcryptZeroRemover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
+	firstIndex := dataLen - blockSize + 1
+	lastIndex := dataLen - 1
+
+	firstPadIndex := dataLen
+	isPadding := true
+	// Always scan *all* data of the last block to thwart timing attacks.
+	for i := lastIndex; i >= firstIndex; i-- {
+		isPadding = isPadding && data[i] == 0
+		if isPadding {
+			firstPadIndex = i
+		}
+	}
+
+	return data[:firstPadIndex], nil
+}
+
+// iso97971M3Remover removes ISO 9797-1 method 3 padding.
+// The length in the prefix block must match the length of the padded data and the padding must consist of zero bytes.
+// It may return an ErrInvalidPadding error and is therefore susceptible to a padding oracle!
+func iso97971M3Remover(prefixBlock []byte, data []byte, dataLen int, paddedDataLen int, blockSize int) ([]byte, error) {
+	// 1. Decode the length. Bytes that do not fit into 64 bits must be zero.
+	var bitLen uint64
+	var highBytes byte
+	for i, b := range prefixBlock {
+		if i < blockSize-8 {
+			highBytes |= b
+		} else {
+			bitLen = bitLen<<8 | uint64(b)
+		}
+	}
+
+	if highBytes != 0 || bitLen&7 != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	// 2. The data must end in the last block, which is empty, if there is no data at all.
+	lastBlockIndex := max(0, dataLen-blockSize)
+	lastBlock := data[lastBlockIndex:dataLen]
+	lastBlockLen := len(lastBlock)
+	unpaddedLen := bitLen >> 3
+	fullBlockDataLen := uint64(paddedDataLen - lastBlockLen)
+	if unpaddedLen < fullBlockDataLen || unpaddedLen-fullBlockDataLen > uint64(lastBlockLen) ||
+		(lastBlockLen != 0 && unpaddedLen == fullBlockDataLen) {
+		return nil, ErrInvalidPadding
+	}
+
+	lastBlockDataLen := int(unpaddedLen - fullBlockDataLen)
+
+	// 3. The padding must consist of zero bytes.
+	// Always scan *all* data of the last block to thwart timing attacks.
+	isValid := true
+	for i, b := range lastBlock {
+		isValid = isValid && (b == 0 || i < lastBlockDataLen)
+	}
+	if !isValid {
+		return nil, ErrInvalidPadding
+	}
+
+	return data[:lastBlockIndex+lastBlockDataLen], nil
+}
+
 // -------- Helper functions --------
 
 func checkLengthByte(data []byte, dataLen int, blockSize int) (int, int, int, byte, int, error) {
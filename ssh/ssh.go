@@ -0,0 +1,158 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ssh implements the framing of SSH binary packets as specified in RFC 4253, section 6:
+//
+//	uint32 packet_length || byte padding_length || payload || random padding
+//
+// The padding consists of at least 4 and at most 255 random bytes, so that the length of the whole packet
+// is a multiple of the cipher block size or 8, whichever is larger.
+// Compression and MAC are not handled by this package.
+package ssh
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/blocksize"
+	"github.com/xformerfhs/blockpad/internal/random"
+)
+
+// ******** Public types ********
+
+// PacketFramer builds and parses SSH binary packets.
+//
+// A PacketFramer is safe for concurrent use by multiple goroutines, as it is used read-only.
+type PacketFramer struct {
+	alignment int
+}
+
+// ******** Private constants ********
+
+// These are the SSH packet parameters.
+const (
+	sshLengthSize    = 4
+	sshHeaderSize    = sshLengthSize + 1
+	sshMinAlignment  = 8
+	sshMinPadLen     = 4
+	sshMaxPadLen     = 255
+	sshMinPacketSize = 16
+)
+
+// ******** Public errors ********
+
+var (
+	// ErrPaddingTooLong means that the requested padding exceeds 255 bytes.
+	ErrPaddingTooLong = errors.New(`padding too long`)
+
+	// ErrInvalidPacketLength means that the length of a packet does not match its length field or the block size.
+	ErrInvalidPacketLength = errors.New(`invalid packet length`)
+)
+
+// ******** Public creation function ********
+
+// NewPacketFramer creates an SSH packet framer for a cipher block size.
+// For stream ciphers the block size is 1.
+func NewPacketFramer(cipherBlockSize int) (*PacketFramer, error) {
+	if !blocksize.IsValid(cipherBlockSize) {
+		return nil, blockpad.ErrInvalidBlockSize
+	}
+
+	alignment := max(sshMinAlignment, cipherBlockSize)
+
+	// The padding needs up to alignment + 3 bytes, which must fit into the padding_length byte.
+	if alignment+sshMinPadLen-1 > sshMaxPadLen {
+		return nil, blockpad.ErrInvalidBlockSize
+	}
+
+	return &PacketFramer{alignment: alignment}, nil
+}
+
+// ******** Public functions ********
+
+// Frame builds a packet from a payload.
+// extraPadBlocks is the number of additional padding blocks that may be added to hide the length of the payload.
+// It returns ErrPaddingTooLong if the padding would be longer than 255 bytes,
+// or the error of the random number generator, if the random padding can not be created.
+func (sf *PacketFramer) Frame(payload []byte, extraPadBlocks int) ([]byte, error) {
+	alignment := sf.alignment
+
+	// 1. Calculate the padding length.
+	payloadLen := len(payload)
+	padLen := alignment - (sshHeaderSize+payloadLen)%alignment
+	if padLen < sshMinPadLen {
+		padLen += alignment
+	}
+
+	if extraPadBlocks < 0 || extraPadBlocks > sshMaxPadLen/alignment {
+		return nil, ErrPaddingTooLong
+	}
+
+	padLen += extraPadBlocks * alignment
+	if padLen > sshMaxPadLen {
+		return nil, ErrPaddingTooLong
+	}
+
+	// 2. Build the packet.
+	packetLen := sshHeaderSize + payloadLen + padLen
+	result := make([]byte, packetLen)
+	binary.BigEndian.PutUint32(result, uint32(packetLen-sshLengthSize))
+	result[sshLengthSize] = byte(padLen)
+	copy(result[sshHeaderSize:], payload)
+
+	err := random.Fill(result[sshHeaderSize+payloadLen:])
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Parse checks a decrypted packet and returns its payload.
+// It returns a byte slice into the supplied packet and does not allocate a new slice.
+// The padding length is checked in constant time.
+// It may return a blockpad.ErrInvalidPadding error and is therefore susceptible to a padding oracle,
+// if the packet has not been authenticated before.
+func (sf *PacketFramer) Parse(packet []byte) ([]byte, error) {
+	// 1. Check the public lengths.
+	packetLen := len(packet)
+	if packetLen < max(sshMinPacketSize, sf.alignment) || packetLen%sf.alignment != 0 {
+		return nil, ErrInvalidPacketLength
+	}
+
+	if uint64(binary.BigEndian.Uint32(packet)) != uint64(packetLen-sshLengthSize) {
+		return nil, ErrInvalidPacketLength
+	}
+
+	// 2. Check the padding length in constant time.
+	padLen := int(packet[sshLengthSize])
+	maxPadLen := min(sshMaxPadLen, packetLen-sshHeaderSize)
+	if subtle.ConstantTimeLessOrEq(sshMinPadLen, padLen)&subtle.ConstantTimeLessOrEq(padLen, maxPadLen) != 1 {
+		return nil, blockpad.ErrInvalidPadding
+	}
+
+	return packet[sshHeaderSize : packetLen-padLen], nil
+}
+
+// Alignment returns the value of which the length of all packets is a multiple.
+func (sf *PacketFramer) Alignment() int {
+	return sf.alignment
+}
@@ -0,0 +1,140 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the maximum payload length in the functional tests.
+const loopCount = 100
+
+// ******** Functional tests ********
+
+func TestFrameParse(t *testing.T) {
+	for _, cipherBlockSize := range []int{1, 8, 16, 32} {
+		framer, err := NewPacketFramer(cipherBlockSize)
+		if err != nil {
+			t.Fatalf(`Error creating SSH packet framer with block size %d: %v`, cipherBlockSize, err)
+		}
+
+		alignment := framer.Alignment()
+
+		for payloadLen := 0; payloadLen <= loopCount; payloadLen++ {
+			payload := testhelper.MakeTestSlice(payloadLen)
+
+			for extraPadBlocks := 0; extraPadBlocks <= 2; extraPadBlocks++ {
+				var packet []byte
+				packet, err = framer.Frame(payload, extraPadBlocks)
+				if err != nil {
+					t.Fatalf(`Framing %d bytes failed: %v`, payloadLen, err)
+				}
+
+				packetLen := len(packet)
+				if packetLen%alignment != 0 || packetLen < 16 {
+					t.Fatalf(`Packet length %d is not a multiple of %d`, packetLen, alignment)
+				}
+				if int(binary.BigEndian.Uint32(packet)) != packetLen-4 {
+					t.Fatalf(`Wrong packet_length field %d for packet length %d`, binary.BigEndian.Uint32(packet), packetLen)
+				}
+
+				padLen := int(packet[4])
+				if padLen < 4+extraPadBlocks*alignment || padLen >= 4+(extraPadBlocks+1)*alignment {
+					t.Fatalf(`Wrong padding length %d for payload length %d`, padLen, payloadLen)
+				}
+
+				var parsedPayload []byte
+				parsedPayload, err = framer.Parse(packet)
+				if err != nil {
+					t.Fatalf(`Parsing packet with payload length %d failed: %v`, payloadLen, err)
+				}
+				if !bytes.Equal(parsedPayload, payload) {
+					t.Fatalf(`Parsed payload differs from payload with length %d`, payloadLen)
+				}
+			}
+		}
+	}
+}
+
+func TestMaxPadding(t *testing.T) {
+	framer, _ := NewPacketFramer(16)
+
+	// 7 bytes of payload need 4 bytes of padding, so 15 extra blocks make exactly 244 bytes of padding.
+	packet, err := framer.Frame(testhelper.MakeTestSlice(7), 15)
+	if err != nil {
+		t.Fatalf(`Framing with maximum padding failed: %v`, err)
+	}
+	if packet[4] != 244 {
+		t.Fatalf(`Wrong maximum padding length %d`, packet[4])
+	}
+
+	// 8 bytes of payload need 19 bytes of padding, so 15 extra blocks exceed 255 bytes.
+	_, err = framer.Frame(testhelper.MakeTestSlice(8), 15)
+	if !errors.Is(err, ErrPaddingTooLong) {
+		t.Fatalf(`Too long padding was not detected: %v`, err)
+	}
+
+	_, err = framer.Frame(nil, -1)
+	if !errors.Is(err, ErrPaddingTooLong) {
+		t.Fatalf(`Negative extra padding was not detected: %v`, err)
+	}
+}
+
+// ******** Error tests ********
+
+func TestInvalidPackets(t *testing.T) {
+	framer, _ := NewPacketFramer(16)
+	packet, _ := framer.Frame(testhelper.MakeTestSlice(20), 0)
+
+	_, err := framer.Parse(packet[:len(packet)-16])
+	if !errors.Is(err, ErrInvalidPacketLength) {
+		t.Fatalf(`Truncated packet was not detected: %v`, err)
+	}
+
+	_, err = framer.Parse(packet[:len(packet)-1])
+	if !errors.Is(err, ErrInvalidPacketLength) {
+		t.Fatalf(`Misaligned packet was not detected: %v`, err)
+	}
+
+	for _, padLen := range []byte{0, 3, byte(len(packet) - 4), 255} {
+		packet[4] = padLen
+		_, err = framer.Parse(packet)
+		if !errors.Is(err, blockpad.ErrInvalidPadding) {
+			t.Fatalf(`Invalid padding length %d was not detected: %v`, padLen, err)
+		}
+	}
+}
+
+func TestInvalidBlockSize(t *testing.T) {
+	for _, cipherBlockSize := range []int{0, 253, 256} {
+		_, err := NewPacketFramer(cipherBlockSize)
+		if !errors.Is(err, blockpad.ErrInvalidBlockSize) {
+			t.Fatalf(`Invalid block size %d was not detected: %v`, cipherBlockSize, err)
+		}
+	}
+}
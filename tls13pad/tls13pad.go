@@ -0,0 +1,109 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tls13pad implements the padding of the inner plaintext of TLS 1.3 records as specified in RFC 8446, section 5.4:
+//
+//	content || content type || zero padding
+//
+// The zero padding hides the length of the content. The inner plaintext is padded
+// to a multiple of a granularity, but never beyond the maximum inner plaintext length of 2^14 + 1 bytes.
+//
+// The package [github.com/xformerfhs/blockpad/tlscbc] implements the record protection of TLS 1.0 to 1.2.
+package tls13pad
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/blocksize"
+)
+
+// ******** Public types ********
+
+// Padder pads and unpads the inner plaintext of TLS 1.3 records.
+//
+// A Padder is safe for concurrent use by multiple goroutines, as it is used read-only.
+type Padder struct {
+	granularity int
+}
+
+// ******** Private constants ********
+
+// maxInnerPlaintextLen is the maximum length of a TLS 1.3 inner plaintext.
+const maxInnerPlaintextLen = 1<<14 + 1
+
+// ******** Public errors ********
+
+// ErrInvalidGranularity means that the granularity is not between 1 and 2^14 + 1.
+var ErrInvalidGranularity = errors.New(`invalid granularity`)
+
+// ******** Public creation function ********
+
+// NewPadder creates a TLS 1.3 inner plaintext padder.
+// The inner plaintext is padded to a multiple of granularity. A granularity of 1 means no padding.
+// The granularity must be between 1 and 2^14 + 1.
+func NewPadder(granularity int) (*Padder, error) {
+	if !blocksize.IsValidUpTo(granularity, maxInnerPlaintextLen) {
+		return nil, ErrInvalidGranularity
+	}
+
+	return &Padder{granularity: granularity}, nil
+}
+
+// ******** Public functions ********
+
+// Pad returns a new slice that contains the content, the content type and the zero padding.
+// The content type must not be 0. If it is, Pad panics.
+func (tp *Padder) Pad(content []byte, contentType byte) []byte {
+	if contentType == 0 {
+		panic(`content type must not be 0`)
+	}
+
+	unpaddedLen := len(content) + 1
+	paddedLen := unpaddedLen + (tp.granularity-unpaddedLen%tp.granularity)%tp.granularity
+	paddedLen = max(unpaddedLen, min(paddedLen, maxInnerPlaintextLen))
+
+	// The padding consists of the zero bytes created by make.
+	result := make([]byte, paddedLen)
+	copy(result, content)
+	result[unpaddedLen-1] = contentType
+
+	return result
+}
+
+// Unpad removes the zero padding from an inner plaintext and returns the content and the content type.
+// It returns a byte slice into the supplied data and does not allocate a new slice.
+// The inner plaintext is scanned in constant time.
+// If the inner plaintext consists only of zero bytes, blockpad.ErrInvalidPadding is returned.
+func (tp *Padder) Unpad(innerPlaintext []byte) ([]byte, byte, error) {
+	typeIndex := -1
+
+	// Always scan *all* data to thwart timing attacks.
+	for i, b := range innerPlaintext {
+		isNonZero := 1 - subtle.ConstantTimeByteEq(b, 0)
+		typeIndex = subtle.ConstantTimeSelect(isNonZero, i, typeIndex)
+	}
+
+	if typeIndex < 0 {
+		return nil, 0, blockpad.ErrInvalidPadding
+	}
+
+	return innerPlaintext[:typeIndex], innerPlaintext[typeIndex], nil
+}
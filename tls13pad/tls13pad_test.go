@@ -0,0 +1,130 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tls13pad
+
+import (
+	"bytes"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/testhelper"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the maximum content length in the functional tests.
+const loopCount = 100
+
+// ******** Functional tests ********
+
+func TestPadUnpad(t *testing.T) {
+	for _, granularity := range []int{1, 16, 256} {
+		padder, err := NewPadder(granularity)
+		if err != nil {
+			t.Fatalf(`Error creating TLS 1.3 padder with granularity %d: %v`, granularity, err)
+		}
+
+		for contentLen := 0; contentLen <= loopCount; contentLen++ {
+			content := testhelper.MakeTestSlice(contentLen)
+
+			padded := padder.Pad(content, 23)
+			if len(padded)%granularity != 0 || len(padded) < contentLen+1 || len(padded) >= contentLen+1+granularity {
+				t.Fatalf(`Wrong padded length %d for content length %d and granularity %d`, len(padded), contentLen, granularity)
+			}
+
+			var unpadded []byte
+			var contentType byte
+			unpadded, contentType, err = padder.Unpad(padded)
+			if err != nil {
+				t.Fatalf(`Unpad with content length %d failed: %v`, contentLen, err)
+			}
+			if contentType != 23 {
+				t.Fatalf(`Wrong content type %d`, contentType)
+			}
+			if !bytes.Equal(unpadded, content) {
+				t.Fatalf(`Unpadded content differs from content with length %d`, contentLen)
+			}
+		}
+	}
+}
+
+func TestTrailingZeroContent(t *testing.T) {
+	padder, _ := NewPadder(32)
+
+	// Zero bytes at the end of the content must be kept, as the content type follows them.
+	content := []byte{1, 2, 0, 0}
+	unpadded, contentType, err := padder.Unpad(padder.Pad(content, 22))
+	if err != nil {
+		t.Fatalf(`Unpad failed: %v`, err)
+	}
+	if contentType != 22 || !bytes.Equal(unpadded, content) {
+		t.Fatalf(`Wrong content %02x or content type %d`, unpadded, contentType)
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	padder, _ := NewPadder(maxInnerPlaintextLen)
+
+	padded := padder.Pad([]byte(`short`), 23)
+	if len(padded) != maxInnerPlaintextLen {
+		t.Fatalf(`Wrong padded length %d`, len(padded))
+	}
+
+	padder, _ = NewPadder(1000)
+
+	padded = padder.Pad(make([]byte, 1<<14), 23)
+	if len(padded) != maxInnerPlaintextLen {
+		t.Fatalf(`Padding exceeds the maximum length: %d`, len(padded))
+	}
+}
+
+// ******** Error tests ********
+
+func TestOnlyZeros(t *testing.T) {
+	padder, _ := NewPadder(16)
+
+	for _, innerPlaintext := range [][]byte{nil, make([]byte, 16)} {
+		_, _, err := padder.Unpad(innerPlaintext)
+		if !errors.Is(err, blockpad.ErrInvalidPadding) {
+			t.Fatalf(`Inner plaintext of %d zero bytes was not detected: %v`, len(innerPlaintext), err)
+		}
+	}
+}
+
+func TestZeroContentType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`No panic with content type 0`)
+		}
+	}()
+
+	padder, _ := NewPadder(16)
+	_ = padder.Pad([]byte(`data`), 0)
+}
+
+func TestInvalidGranularity(t *testing.T) {
+	for _, granularity := range []int{0, -1, maxInnerPlaintextLen + 1} {
+		_, err := NewPadder(granularity)
+		if !errors.Is(err, ErrInvalidGranularity) {
+			t.Fatalf(`Invalid granularity %d was not detected: %v`, granularity, err)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tlscbc implements the protection of TLS 1.0, 1.1 and 1.2 records with a block cipher in CBC mode
+// and an HMAC (MAC-then-encrypt, RFC 5246, section 6.2.3.2).
+//
+// A record fragment consists of the explicit initialization vector (TLS 1.1 and 1.2)
+// and the encrypted content, HMAC and padding. The padding consists of padding_length + 1 bytes
+// that all have the value padding_length, so it may span multiple blocks.
+// The HMAC covers the sequence number, the record type, the protocol version, the content length and the content.
+//
+// Decryption implements countermeasures against Lucky13: the padding is checked in constant time,
+// the received HMAC is extracted in constant time and padding and HMAC failures yield the same error.
+// Like crypto/tls, the HMAC calculation is only padded on a best-effort basis:
+// after the HMAC has been calculated, the hash is fed with as many bytes as the padding is long.
+// This reduces, but does not remove, the dependency of the number of hash compressions on the padding length,
+// so a small timing difference remains.
+package tlscbc
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"github.com/xformerfhs/blockpad/internal/slicehelper"
+	"hash"
+)
+
+// ******** Public types ********
+
+// Encrypter protects the records of one direction of a TLS connection.
+// It keeps the sequence number and, for TLS 1.0, the CBC state, so it must not be used concurrently.
+type Encrypter struct {
+	recordProtection
+	mode cipher.BlockMode
+}
+
+// Decrypter checks and decrypts the records of one direction of a TLS connection.
+// It keeps the sequence number and, for TLS 1.0, the CBC state, so it must not be used concurrently.
+type Decrypter struct {
+	recordProtection
+	mode cipher.BlockMode
+}
+
+// ******** Public errors ********
+
+var (
+	// ErrBadRecordMAC means that the padding or the HMAC of a record is invalid.
+	// Both cases deliberately yield the same error so that there is no padding oracle.
+	ErrBadRecordMAC = errors.New(`bad record MAC`)
+
+	// ErrInvalidVersion means that the protocol version is not TLS 1.0, 1.1 or 1.2.
+	ErrInvalidVersion = errors.New(`invalid TLS version`)
+
+	// ErrInvalidMACKey means that the MAC key is empty.
+	ErrInvalidMACKey = errors.New(`invalid MAC key`)
+)
+
+// ******** Private types ********
+
+// recordProtection holds the data that are common to encrypter and decrypter.
+type recordProtection struct {
+	block      cipher.Block
+	mac        hash.Hash
+	version    uint16
+	blockSize  int
+	macSize    int
+	explicitIV bool
+	seq        uint64
+}
+
+// cbcMode is the interface of the CBC modes of the standard library that allow to set the initialization vector.
+type cbcMode interface {
+	cipher.BlockMode
+	SetIV([]byte)
+}
+
+// ******** Private constants ********
+
+// headerSize is the size of the data that precede the content in the HMAC.
+const headerSize = 8 + 1 + 2 + 2
+
+// maxPadLen is the maximum value of padding_length.
+const maxPadLen = 255
+
+// ******** Public creation functions ********
+
+// NewEncrypter creates an Encrypter for a TLS version, a block cipher, a hash constructor, e.g. sha256.New, and a MAC key.
+// iv is the initialization vector from the key block for TLS 1.0. It is ignored for TLS 1.1 and 1.2,
+// which use a random explicit initialization vector for every record.
+func NewEncrypter(version uint16, block cipher.Block, hashFunc func() hash.Hash, macKey []byte, iv []byte) (*Encrypter, error) {
+	rp, err := newRecordProtection(version, block, hashFunc, macKey, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encrypter{recordProtection: *rp, mode: cipher.NewCBCEncrypter(block, rp.initialIV(iv))}, nil
+}
+
+// NewDecrypter creates a Decrypter for a TLS version, a block cipher, a hash constructor, e.g. sha256.New, and a MAC key.
+// iv is the initialization vector from the key block for TLS 1.0. It is ignored for TLS 1.1 and 1.2,
+// which read the explicit initialization vector from every record.
+func NewDecrypter(version uint16, block cipher.Block, hashFunc func() hash.Hash, macKey []byte, iv []byte) (*Decrypter, error) {
+	rp, err := newRecordProtection(version, block, hashFunc, macKey, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decrypter{recordProtection: *rp, mode: cipher.NewCBCDecrypter(block, rp.initialIV(iv))}, nil
+}
+
+// ******** Public functions ********
+
+// Seal protects the content of a record of type recordType and appends the fragment to dst.
+// It returns the updated slice. dst must not overlap content.
+// The content must not be longer than the maximum TLS record size of 16384 bytes.
+func (e *Encrypter) Seal(dst []byte, recordType byte, content []byte) []byte {
+	blockSize := e.blockSize
+	contentLen := len(content)
+
+	// 1. Calculate the lengths.
+	padLen := blockSize - 1 - (contentLen+e.macSize)%blockSize
+	encryptedLen := contentLen + e.macSize + padLen + 1
+	ivLen := 0
+	if e.explicitIV {
+		ivLen = blockSize
+	}
+
+	result, out := slicehelper.ForAppend(dst, ivLen+encryptedLen)
+
+	// 2. Create the explicit initialization vector.
+	if e.explicitIV {
+		_, _ = rand.Read(out[:ivLen])
+		e.mode.(cbcMode).SetIV(out[:ivLen])
+		out = out[ivLen:]
+	}
+
+	// 3. Build content, HMAC and padding.
+	copy(out, content)
+	e.calculateMAC(out[contentLen:contentLen], recordType, content)
+	slicehelper.Fill(out[contentLen+e.macSize:], byte(padLen))
+
+	// 4. Encrypt.
+	e.mode.CryptBlocks(out, out)
+	e.seq++
+
+	return result
+}
+
+// Overhead returns the maximum difference between the lengths of a fragment and its content.
+func (e *Encrypter) Overhead() int {
+	return e.overhead()
+}
+
+// Open checks and decrypts the fragment of a record of type recordType and appends the content to dst.
+// It returns the updated slice. dst must not overlap fragment.
+// If the padding or the HMAC are invalid, ErrBadRecordMAC is returned.
+// The timing of Open depends only slightly on the padding length, see the package documentation.
+func (d *Decrypter) Open(dst []byte, recordType byte, fragment []byte) ([]byte, error) {
+	blockSize := d.blockSize
+	macSize := d.macSize
+
+	// 1. Check the public lengths.
+	if d.explicitIV {
+		if len(fragment) < blockSize {
+			return nil, ErrBadRecordMAC
+		}
+
+		d.mode.(cbcMode).SetIV(fragment[:blockSize])
+		fragment = fragment[blockSize:]
+	}
+
+	encryptedLen := len(fragment)
+	if encryptedLen%blockSize != 0 || encryptedLen < ((macSize+1+blockSize-1)/blockSize)*blockSize {
+		return nil, ErrBadRecordMAC
+	}
+
+	// 2. Decrypt.
+	dstLen := len(dst)
+	result, plain := slicehelper.ForAppend(dst, encryptedLen)
+	d.mode.CryptBlocks(plain, fragment)
+
+	// 3. Check the padding. On failure, the padding is treated as 1 byte, so the remaining work is the same.
+	padLen, isPaddingValid := extractPadding(plain, macSize)
+	contentLen := encryptedLen - padLen - 1 - macSize
+
+	// 4. Calculate the HMAC and then hash the padding bytes, so that the hash work depends less on the padding length.
+	// This is a best-effort countermeasure, as the number of compressions in Sum is not fixed.
+	expectedMAC := d.calculateMAC(make([]byte, 0, macSize), recordType, plain[:contentLen])
+	d.mac.Write(plain[:padLen])
+
+	// 5. Extract the received HMAC from its secret position and compare.
+	receivedMAC := extractMAC(plain, contentLen, macSize)
+	if subtle.ConstantTimeCompare(expectedMAC, receivedMAC)&isPaddingValid != 1 {
+		return nil, ErrBadRecordMAC
+	}
+
+	d.seq++
+
+	return result[:dstLen+contentLen], nil
+}
+
+// ******** Private functions ********
+
+// newRecordProtection checks the parameters and creates the common data.
+func newRecordProtection(version uint16, block cipher.Block, hashFunc func() hash.Hash, macKey []byte, iv []byte) (*recordProtection, error) {
+	if version != tls.VersionTLS10 && version != tls.VersionTLS11 && version != tls.VersionTLS12 {
+		return nil, ErrInvalidVersion
+	}
+
+	if len(macKey) == 0 {
+		return nil, ErrInvalidMACKey
+	}
+
+	blockSize := block.BlockSize()
+	explicitIV := version != tls.VersionTLS10
+	if !explicitIV && len(iv) != blockSize {
+		return nil, blockpad.ErrInvalidIV
+	}
+
+	mac := hmac.New(hashFunc, macKey)
+
+	return &recordProtection{
+		block:      block,
+		mac:        mac,
+		version:    version,
+		blockSize:  blockSize,
+		macSize:    mac.Size(),
+		explicitIV: explicitIV,
+	}, nil
+}
+
+// initialIV returns the initialization vector of the CBC mode.
+// For TLS 1.1 and 1.2 it is replaced on every record, so a zero initialization vector is used.
+func (rp *recordProtection) initialIV(iv []byte) []byte {
+	if rp.explicitIV {
+		return make([]byte, rp.blockSize)
+	}
+
+	return iv
+}
+
+// calculateMAC calculates the HMAC of the content and appends it to dst.
+// The hash is not reset after the calculation, so that more dummy data can be written to it.
+func (rp *recordProtection) calculateMAC(dst []byte, recordType byte, content []byte) []byte {
+	var header [headerSize]byte
+	binary.BigEndian.PutUint64(header[:], rp.seq)
+	header[8] = recordType
+	binary.BigEndian.PutUint16(header[9:], rp.version)
+	binary.BigEndian.PutUint16(header[11:], uint16(len(content)))
+
+	rp.mac.Reset()
+	rp.mac.Write(header[:])
+	rp.mac.Write(content)
+
+	return rp.mac.Sum(dst)
+}
+
+// overhead returns the maximum difference between the lengths of a fragment and its content.
+func (rp *recordProtection) overhead() int {
+	result := rp.macSize + rp.blockSize
+	if rp.explicitIV {
+		result += rp.blockSize
+	}
+
+	return result
+}
+
+// extractPadding checks the padding in constant time and returns the padding length and 1 if it is valid.
+// If the padding is invalid, a padding length of 0 and 0 are returned.
+// All bytes that may be padding are always scanned to thwart timing attacks.
+func extractPadding(plain []byte, macSize int) (int, int) {
+	plainLen := len(plain)
+	lastIndex := plainLen - 1
+	padLenByte := plain[lastIndex]
+	padLen := int(padLenByte)
+
+	// The padding and the HMAC must fit into the data.
+	isValid := subtle.ConstantTimeLessOrEq(padLen+1+macSize, plainLen)
+
+	toCheck := min(maxPadLen+1, plainLen)
+	for i := 0; i < toCheck; i++ {
+		isPadding := subtle.ConstantTimeLessOrEq(i, padLen)
+		isEqual := subtle.ConstantTimeByteEq(plain[lastIndex-i], padLenByte)
+		isValid &= subtle.ConstantTimeSelect(isPadding, isEqual, 1)
+	}
+
+	return subtle.ConstantTimeSelect(isValid, padLen, 0), isValid
+}
+
+// extractMAC copies the HMAC at the secret position macStart in constant time.
+// All positions at which the HMAC may start are scanned.
+func extractMAC(plain []byte, macStart int, macSize int) []byte {
+	result := make([]byte, macSize)
+
+	lastStart := len(plain) - 1 - macSize
+	firstStart := max(0, lastStart-maxPadLen)
+	for start := firstStart; start <= lastStart; start++ {
+		mask := byte(subtle.ConstantTimeEq(int32(start), int32(macStart)) * 0xff)
+		for i, b := range plain[start : start+macSize] {
+			result[i] |= b & mask
+		}
+	}
+
+	return result
+}
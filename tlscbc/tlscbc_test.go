@@ -0,0 +1,252 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscbc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"github.com/xformerfhs/blockpad"
+	"hash"
+	"testing"
+)
+
+// ******** Private constants ********
+
+// loopCount is the number of content lengths in the functional tests.
+const loopCount = 100
+
+// recordType is the record type used in the tests (application_data).
+const recordType = 23
+
+// These are the keys used in the tests.
+var (
+	testKey    = []byte(`0123456789abcdef01234567`)
+	testMACKey = []byte(`MAC key for the TLS CBC tests`)
+	testIV     = []byte(`initialization v`)
+)
+
+// ******** Functional tests ********
+
+func TestSealOpen(t *testing.T) {
+	for _, version := range []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12} {
+		for _, hashFunc := range []func() hash.Hash{sha1.New, sha256.New} {
+			for _, newBlock := range []func([]byte) (cipher.Block, error){newAES, des.NewTripleDESCipher} {
+				block, _ := newBlock(testKey)
+				iv := testIV[:block.BlockSize()]
+
+				encrypter, decrypter := makeEncrypterAndDecrypter(t, version, block, hashFunc, iv)
+
+				for contentLen := 0; contentLen <= loopCount; contentLen++ {
+					content := bytes.Repeat([]byte{'t'}, contentLen)
+
+					fragment := encrypter.Seal(nil, recordType, content)
+					if len(fragment) > contentLen+encrypter.Overhead() {
+						t.Fatalf(`Fragment length %d exceeds overhead for content length %d`, len(fragment), contentLen)
+					}
+
+					decrypted, err := decrypter.Open(nil, recordType, fragment)
+					if err != nil {
+						t.Fatalf(`Version %x: Open of content length %d failed: %v`, version, contentLen, err)
+					}
+					if !bytes.Equal(decrypted, content) {
+						t.Fatalf(`Version %x: decrypted content differs from content with length %d`, version, contentLen)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestOpenMultiBlockPadding(t *testing.T) {
+	block, _ := aes.NewCipher(testKey[:16])
+	_, decrypter := makeEncrypterAndDecrypter(t, tls.VersionTLS12, block, sha256.New, nil)
+
+	for _, padLen := range []int{0, 15, 100, 255} {
+		fragment, content := buildRecord(block, uint64(padLen), []byte(`multi block padding`), padLen, nil)
+
+		// The sequence number is set to the value used for building the record.
+		decrypter.seq = uint64(padLen)
+		decrypted, err := decrypter.Open(nil, recordType, fragment)
+		if err != nil {
+			t.Fatalf(`Open with padding length %d failed: %v`, padLen, err)
+		}
+		if !bytes.Equal(decrypted, content) {
+			t.Fatalf(`Decrypted content differs from content with padding length %d`, padLen)
+		}
+	}
+}
+
+// ******** Error tests ********
+
+func TestOpenBadRecords(t *testing.T) {
+	block, _ := aes.NewCipher(testKey[:16])
+	_, decrypter := makeEncrypterAndDecrypter(t, tls.VersionTLS12, block, sha256.New, nil)
+	content := []byte(`bad record content`)
+
+	// Invalid padding byte in the first of several padding blocks.
+	fragment, _ := buildRecord(block, 0, content, 100, func(plain []byte, _ int) {
+		plain[len(plain)-90] ^= 1
+	})
+	checkBadRecord(t, decrypter, fragment, `invalid padding byte`)
+
+	// Padding length that is longer than the data.
+	fragment, _ = buildRecord(block, 0, content, 15, func(plain []byte, _ int) {
+		plain[len(plain)-1] = 200
+	})
+	checkBadRecord(t, decrypter, fragment, `too long padding`)
+
+	// Invalid HMAC.
+	fragment, _ = buildRecord(block, 0, content, 15, func(plain []byte, contentLen int) {
+		plain[contentLen] ^= 1
+	})
+	checkBadRecord(t, decrypter, fragment, `invalid HMAC`)
+
+	// Invalid content.
+	fragment, _ = buildRecord(block, 0, content, 15, func(plain []byte, _ int) {
+		plain[0] ^= 1
+	})
+	checkBadRecord(t, decrypter, fragment, `modified content`)
+
+	// Wrong record type.
+	fragment, _ = buildRecord(block, 0, content, 15, nil)
+	decrypter.seq = 0
+	_, err := decrypter.Open(nil, recordType+1, fragment)
+	if !errors.Is(err, ErrBadRecordMAC) {
+		t.Fatalf(`Wrong record type was not detected: %v`, err)
+	}
+
+	// Invalid lengths.
+	for _, fragmentLen := range []int{0, 15, 16, 47, 63} {
+		_, err = decrypter.Open(nil, recordType, make([]byte, fragmentLen))
+		if !errors.Is(err, ErrBadRecordMAC) {
+			t.Fatalf(`Invalid fragment length %d was not detected: %v`, fragmentLen, err)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	block, _ := aes.NewCipher(testKey[:16])
+	encrypter, decrypter := makeEncrypterAndDecrypter(t, tls.VersionTLS12, block, sha256.New, nil)
+
+	fragment := encrypter.Seal(nil, recordType, []byte(`replayed`))
+
+	_, err := decrypter.Open(nil, recordType, fragment)
+	if err != nil {
+		t.Fatalf(`Open failed: %v`, err)
+	}
+
+	_, err = decrypter.Open(nil, recordType, fragment)
+	if !errors.Is(err, ErrBadRecordMAC) {
+		t.Fatalf(`Replayed record was not detected: %v`, err)
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	block, _ := aes.NewCipher(testKey[:16])
+
+	_, err := NewDecrypter(tls.VersionTLS13, block, sha256.New, testMACKey, nil)
+	if !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf(`Invalid version was not detected: %v`, err)
+	}
+
+	_, err = NewDecrypter(tls.VersionTLS12, block, sha256.New, nil, nil)
+	if !errors.Is(err, ErrInvalidMACKey) {
+		t.Fatalf(`Empty MAC key was not detected: %v`, err)
+	}
+
+	_, err = NewEncrypter(tls.VersionTLS10, block, sha256.New, testMACKey, testIV[:8])
+	if !errors.Is(err, blockpad.ErrInvalidIV) {
+		t.Fatalf(`Invalid IV was not detected: %v`, err)
+	}
+}
+
+// ******** Private functions ********
+
+// newAES creates an AES-128 cipher from the first 16 bytes of the key.
+func newAES(key []byte) (cipher.Block, error) {
+	return aes.NewCipher(key[:16])
+}
+
+// makeEncrypterAndDecrypter creates an encrypter and a decrypter with the test keys.
+func makeEncrypterAndDecrypter(t *testing.T, version uint16, block cipher.Block, hashFunc func() hash.Hash, iv []byte) (*Encrypter, *Decrypter) {
+	encrypter, err := NewEncrypter(version, block, hashFunc, testMACKey, iv)
+	if err != nil {
+		t.Fatalf(`Could not create encrypter: %v`, err)
+	}
+
+	var decrypter *Decrypter
+	decrypter, err = NewDecrypter(version, block, hashFunc, testMACKey, iv)
+	if err != nil {
+		t.Fatalf(`Could not create decrypter: %v`, err)
+	}
+
+	return encrypter, decrypter
+}
+
+// buildRecord builds a TLS 1.2 record fragment with HMAC-SHA256 and a given padding length independently of Seal.
+// The content is prefixed with as many 'x' bytes as are needed to keep the padding length.
+// modify is called with the plaintext and the content length before the plaintext is encrypted, if it is not nil.
+// It returns the fragment and the prefixed content.
+func buildRecord(block cipher.Block, seq uint64, content []byte, padLen int, modify func([]byte, int)) ([]byte, []byte) {
+	blockSize := block.BlockSize()
+
+	fillLen := (blockSize - (len(content)+sha256.Size+padLen+1)%blockSize) % blockSize
+	content = append(bytes.Repeat([]byte{'x'}, fillLen), content...)
+
+	mac := hmac.New(sha256.New, testMACKey)
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint64(header, seq)
+	header[8] = recordType
+	binary.BigEndian.PutUint16(header[9:], tls.VersionTLS12)
+	binary.BigEndian.PutUint16(header[11:], uint16(len(content)))
+	mac.Write(header)
+	mac.Write(content)
+
+	plain := append([]byte(nil), content...)
+	plain = mac.Sum(plain)
+	plain = append(plain, bytes.Repeat([]byte{byte(padLen)}, padLen+1)...)
+
+	if modify != nil {
+		modify(plain, len(content))
+	}
+
+	result := make([]byte, blockSize+len(plain))
+	cipher.NewCBCEncrypter(block, result[:blockSize]).CryptBlocks(result[blockSize:], plain)
+
+	return result, content
+}
+
+// checkBadRecord checks that a record is rejected with ErrBadRecordMAC.
+func checkBadRecord(t *testing.T, decrypter *Decrypter, fragment []byte, reason string) {
+	decrypter.seq = 0
+	_, err := decrypter.Open(nil, recordType, fragment)
+	if !errors.Is(err, ErrBadRecordMAC) {
+		t.Fatalf(`Record with %s was not rejected: %v`, reason, err)
+	}
+}
@@ -0,0 +1,285 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+import (
+	"crypto/cipher"
+	"github.com/xformerfhs/blockpad/internal/padlen"
+	"io"
+)
+
+// ******** This file contains the streaming padding writer ********
+
+// ******** Public types ********
+
+// Writer is an [io.WriteCloser] that pads the data written to it.
+// Full blocks are passed through to the underlying writer as soon as they are complete.
+// Only the last data that does not fit into a full block is held back.
+// The padded last block is written when Close is called.
+//
+// If the Writer has been created with a block mode, all blocks are encrypted
+// with this block mode before they are written to the underlying writer.
+//
+// If the pad algorithm prepends a prefix block, this block depends on the length of all data.
+// Then the Writer has to be created with NewSizedWriter or NewSizedCryptWriter,
+// so that the prefix block can be written before the data.
+//
+// A Writer is not safe for concurrent use by multiple goroutines.
+type Writer struct {
+	padder    *BlockPad
+	dest      io.Writer
+	mode      cipher.BlockMode
+	lastBlock []byte
+	lastLen   int
+	chunk     []byte
+	err       error
+	isClosed  bool
+
+	// dataLen is the announced length of all data or unknownDataLen.
+	// written is the length of the data written so far.
+	dataLen       int
+	written       int
+	prefixWritten bool
+}
+
+// ******** Private constants ********
+
+// unknownDataLen means that the length of the data written to a Writer is not known in advance.
+const unknownDataLen = -1
+
+// ******** Public creation functions ********
+
+// NewWriter creates a Writer that writes the padded data to w.
+// If the pad algorithm prepends a prefix block, ErrUnknownDataLen is returned.
+func (pb *BlockPad) NewWriter(w io.Writer) (*Writer, error) {
+	if pb.PrefixLen() != 0 {
+		return nil, ErrUnknownDataLen
+	}
+
+	return pb.newWriter(w, unknownDataLen), nil
+}
+
+// NewCryptWriter creates a Writer that writes the padded data to w
+// after it has been processed by the block mode, e.g. a CBC encrypter.
+// The block size of the block mode must be the same as the block size of the padder.
+// If the pad algorithm prepends a prefix block, ErrUnknownDataLen is returned.
+func (pb *BlockPad) NewCryptWriter(w io.Writer, mode cipher.BlockMode) (*Writer, error) {
+	if pb.PrefixLen() != 0 {
+		return nil, ErrUnknownDataLen
+	}
+
+	return pb.newCryptWriter(w, mode, unknownDataLen)
+}
+
+// NewSizedWriter creates a Writer that writes the padded data of length dataLen to w.
+// The prefix block, if there is one, is written with the first data.
+// Writing more or less than dataLen bytes returns ErrDataLenMismatch.
+func (pb *BlockPad) NewSizedWriter(w io.Writer, dataLen int) (*Writer, error) {
+	if dataLen < 0 {
+		return nil, ErrDataLenMismatch
+	}
+
+	return pb.newWriter(w, dataLen), nil
+}
+
+// NewSizedCryptWriter creates a Writer that writes the padded data of length dataLen to w
+// after it has been processed by the block mode, e.g. a CBC encrypter.
+// The block size of the block mode must be the same as the block size of the padder.
+// The prefix block, if there is one, is written with the first data.
+// Writing more or less than dataLen bytes returns ErrDataLenMismatch.
+func (pb *BlockPad) NewSizedCryptWriter(w io.Writer, mode cipher.BlockMode, dataLen int) (*Writer, error) {
+	if dataLen < 0 {
+		return nil, ErrDataLenMismatch
+	}
+
+	return pb.newCryptWriter(w, mode, dataLen)
+}
+
+// ******** Public functions ********
+
+// Write writes data to the Writer.
+// It implements the [io.Writer] interface.
+// Data that does not fill a full block is held back until more data is written or the Writer is closed.
+func (pw *Writer) Write(data []byte) (int, error) {
+	if pw.isClosed {
+		return 0, ErrWriterClosed
+	}
+
+	if pw.err != nil {
+		return 0, pw.err
+	}
+
+	// The data must not exceed the announced length.
+	if pw.dataLen != unknownDataLen {
+		if len(data) > pw.dataLen-pw.written {
+			return 0, ErrDataLenMismatch
+		}
+
+		pw.err = pw.writePrefixBlock()
+		if pw.err != nil {
+			return 0, pw.err
+		}
+	}
+
+	n, err := pw.writeData(data)
+	pw.written += n
+
+	return n, err
+}
+
+// Close pads the held back data and writes the padded last block.
+// It does not close the underlying writer.
+// It implements the [io.Closer] interface.
+func (pw *Writer) Close() error {
+	if pw.isClosed {
+		return nil
+	}
+
+	pw.isClosed = true
+
+	if pw.err != nil {
+		return pw.err
+	}
+
+	// 1. All announced data must have been written.
+	// The prefix block has not been written, yet, if there is no data.
+	if pw.dataLen != unknownDataLen {
+		if pw.written != pw.dataLen {
+			pw.err = ErrDataLenMismatch
+			return pw.err
+		}
+
+		pw.err = pw.writePrefixBlock()
+		if pw.err != nil {
+			return pw.err
+		}
+	}
+
+	// 2. Write the padded last block.
+	_, paddedLastBlock := pw.padder.PadLastBlock(pw.lastBlock[:pw.lastLen])
+	pw.lastLen = 0
+
+	pw.err = pw.writeBlocks(paddedLastBlock)
+
+	return pw.err
+}
+
+// ******** Private functions ********
+
+// newWriter creates a Writer for data of length dataLen, which may be unknownDataLen.
+func (pb *BlockPad) newWriter(w io.Writer, dataLen int) *Writer {
+	return &Writer{
+		padder:    pb,
+		dest:      w,
+		lastBlock: make([]byte, pb.blockSize),
+		dataLen:   dataLen,
+	}
+}
+
+// newCryptWriter creates a Writer with a block mode for data of length dataLen, which may be unknownDataLen.
+func (pb *BlockPad) newCryptWriter(w io.Writer, mode cipher.BlockMode, dataLen int) (*Writer, error) {
+	if mode.BlockSize() != pb.blockSize {
+		return nil, ErrInvalidBlockMode
+	}
+
+	result := pb.newWriter(w, dataLen)
+	result.mode = mode
+	result.chunk = make([]byte, streamChunkBlockCount*pb.blockSize)
+
+	return result, nil
+}
+
+// writeData writes full blocks to the underlying writer and holds back the remaining data.
+// It returns the number of bytes of data that have been accepted.
+func (pw *Writer) writeData(data []byte) (int, error) {
+	blockSize := pw.padder.blockSize
+	written := 0
+
+	// 1. Complete a partially filled last block, if there is one.
+	if pw.lastLen > 0 {
+		copyLen := copy(pw.lastBlock[pw.lastLen:], data)
+		pw.lastLen += copyLen
+		if pw.lastLen < blockSize {
+			return len(data), nil
+		}
+
+		pw.err = pw.writeBlocks(pw.lastBlock)
+		if pw.err != nil {
+			return copyLen, pw.err
+		}
+
+		pw.lastLen = 0
+		written = copyLen
+		data = data[copyLen:]
+	}
+
+	// 2. Pass through all full blocks.
+	fullBlockDataLen, _, _ := padlen.Lengths(len(data), blockSize)
+	pw.err = pw.writeBlocks(data[:fullBlockDataLen])
+	if pw.err != nil {
+		return written, pw.err
+	}
+
+	// 3. Hold back the remaining data.
+	pw.lastLen = copy(pw.lastBlock, data[fullBlockDataLen:])
+
+	return written + len(data), nil
+}
+
+// writePrefixBlock writes the prefix block, if the pad algorithm has one and it has not been written, yet.
+func (pw *Writer) writePrefixBlock() error {
+	if pw.padder.PrefixLen() == 0 || pw.prefixWritten {
+		return nil
+	}
+
+	pw.prefixWritten = true
+
+	return pw.writeBlocks(pw.padder.PrefixBlock(pw.dataLen))
+}
+
+// writeBlocks writes full blocks to the underlying writer.
+// If there is a block mode, the blocks are processed chunk by chunk,
+// so that the data of the caller is never modified.
+func (pw *Writer) writeBlocks(blocks []byte) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	if pw.mode == nil {
+		_, err := pw.dest.Write(blocks)
+		return err
+	}
+
+	for len(blocks) > 0 {
+		chunkLen := copy(pw.chunk, blocks)
+		chunk := pw.chunk[:chunkLen]
+		pw.mode.CryptBlocks(chunk, chunk)
+
+		_, err := pw.dest.Write(chunk)
+		if err != nil {
+			return err
+		}
+
+		blocks = blocks[chunkLen:]
+	}
+
+	return nil
+}
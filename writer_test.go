@@ -0,0 +1,376 @@
+//
+// SPDX-FileCopyrightText: Copyright 2026 Frank Schwab
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileType: SOURCE
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// You may not use this file except in compliance with the License.
+//
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockpad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"errors"
+	"io"
+	mrand "math/rand"
+	"testing"
+)
+
+// ******** Functional tests ********
+
+func TestWriterAll(t *testing.T) {
+	for padType := Zero; padType <= maxAlgorithm; padType++ {
+		padder, err := NewBlockPadding(padType, testBlockSize)
+		if err != nil {
+			t.Fatalf(`Error creating BlockPad with pad type %d: %v`, padType, err)
+		}
+
+		for i := 0; i < loopCount; i++ {
+			_, data := makeZeroSafeRandomLenTestSlice(padType)
+
+			var result bytes.Buffer
+			var writer *Writer
+
+			// A padding with a prefix block needs the data length in advance.
+			if padder.PrefixLen() != 0 {
+				writer, err = padder.NewSizedWriter(&result, len(data))
+			} else {
+				writer, err = padder.NewWriter(&result)
+			}
+			if err != nil {
+				t.Fatalf(`%s: could not create writer: %v`, padder.String(), err)
+			}
+
+			writeInRandomPieces(t, writer, data)
+
+			unpaddedData, err := padder.Unpad(result.Bytes())
+			if err != nil {
+				t.Fatalf(`%s: Unpad of written data failed: %v`, padder.String(), err)
+			}
+			if !bytes.Equal(unpaddedData, data) {
+				t.Fatalf("%s: unpaddedData != data:\n        data=%02x\nunpaddedData=%02x",
+					padder.String(),
+					data, unpaddedData)
+			}
+		}
+	}
+}
+
+func TestWriterDeterministic(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	for dataLen := 0; dataLen <= 3*testBlockSize; dataLen++ {
+		data := makeTestSlice(dataLen)
+
+		var result bytes.Buffer
+		writer, err := padder.NewWriter(&result)
+		if err != nil {
+			t.Fatalf(`Could not create writer: %v`, err)
+		}
+
+		writeInRandomPieces(t, writer, data)
+
+		if !bytes.Equal(result.Bytes(), padder.Pad(data)) {
+			t.Fatalf(`%s: written data differs from padded data (dataLen=%d)`, padder.String(), dataLen)
+		}
+	}
+}
+
+func TestCryptWriter(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	key := makeTestSlice(32)
+	iv := makeTestSlice(aes.BlockSize)
+
+	aesCipher, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	for i := 0; i < loopCount; i++ {
+		// Use data larger than a chunk to test the chunking.
+		data := makeTestSlice(mrand.Intn(streamChunkBlockCount*aes.BlockSize*3) + 1)
+		dataCopy := bytes.Clone(data)
+
+		var result bytes.Buffer
+		var writer *Writer
+		writer, err = padder.NewCryptWriter(&result, cipher.NewCBCEncrypter(aesCipher, iv))
+		if err != nil {
+			t.Fatalf(`Could not create crypt writer: %v`, err)
+		}
+
+		writeInRandomPieces(t, writer, data)
+
+		if !bytes.Equal(data, dataCopy) {
+			t.Fatal(`Crypt writer modified the data of the caller`)
+		}
+
+		expected := padder.Pad(data)
+		cipher.NewCBCEncrypter(aesCipher, iv).CryptBlocks(expected, expected)
+		if !bytes.Equal(result.Bytes(), expected) {
+			t.Fatalf(`Crypt writer result differs from encrypted padded data (dataLen=%d)`, len(data))
+		}
+	}
+}
+
+func TestCryptWriterPrefixBlock(t *testing.T) {
+	padder, err := NewBlockPadding(ISO97971M3, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, ISO97971M3, err)
+	}
+
+	iv := makeTestSlice(aes.BlockSize)
+	aesCipher, err := aes.NewCipher(makeTestSlice(32))
+	if err != nil {
+		t.Fatalf(`Could not create AES cipher: %v`, err)
+	}
+
+	for dataLen := 0; dataLen <= 3*aes.BlockSize; dataLen++ {
+		data := makeTestSlice(dataLen)
+
+		var result bytes.Buffer
+		var writer *Writer
+		writer, err = padder.NewSizedCryptWriter(&result, cipher.NewCBCEncrypter(aesCipher, iv), dataLen)
+		if err != nil {
+			t.Fatalf(`Could not create crypt writer: %v`, err)
+		}
+
+		writeInRandomPieces(t, writer, data)
+
+		// The prefix block is encrypted as the first block.
+		expected := padder.Pad(data)
+		cipher.NewCBCEncrypter(aesCipher, iv).CryptBlocks(expected, expected)
+		if !bytes.Equal(result.Bytes(), expected) {
+			t.Fatalf(`%s: crypt writer result differs from encrypted padded data (dataLen=%d)`, padder.String(), dataLen)
+		}
+
+		var reader *Reader
+		reader, err = padder.NewCryptReader(&result, cipher.NewCBCDecrypter(aesCipher, iv))
+		if err != nil {
+			t.Fatalf(`Could not create crypt reader: %v`, err)
+		}
+
+		var decryptedData []byte
+		decryptedData, err = io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf(`%s: reading encrypted data failed (dataLen=%d): %v`, padder.String(), dataLen, err)
+		}
+		if !bytes.Equal(decryptedData, data) {
+			t.Fatalf(`%s: decrypted data differs from data (dataLen=%d)`, padder.String(), dataLen)
+		}
+	}
+}
+
+// ******** Test invalid usage ********
+
+func TestWriteAfterClose(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	var result bytes.Buffer
+	writer, err := padder.NewWriter(&result)
+	if err != nil {
+		t.Fatalf(`Could not create writer: %v`, err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf(`Close failed: %v`, err)
+	}
+
+	_, err = writer.Write([]byte(`too late`))
+	if !errors.Is(err, ErrWriterClosed) {
+		t.Fatalf(`Wrong error writing to closed writer: %v`, err)
+	}
+
+	if result.Len() != testBlockSize {
+		t.Fatalf(`Closed empty writer wrote %d bytes instead of one block`, result.Len())
+	}
+}
+
+func TestWriterPrefixBlockUnknownDataLen(t *testing.T) {
+	padder, err := NewBlockPadding(ISO97971M3, aes.BlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, ISO97971M3, err)
+	}
+
+	var result bytes.Buffer
+	_, err = padder.NewWriter(&result)
+	if !errors.Is(err, ErrUnknownDataLen) {
+		t.Fatalf(`Wrong error creating writer without data length: %v`, err)
+	}
+
+	aesCipher, _ := aes.NewCipher(makeTestSlice(32))
+	_, err = padder.NewCryptWriter(&result, cipher.NewCBCEncrypter(aesCipher, makeTestSlice(aes.BlockSize)))
+	if !errors.Is(err, ErrUnknownDataLen) {
+		t.Fatalf(`Wrong error creating crypt writer without data length: %v`, err)
+	}
+}
+
+func TestSizedWriterDataLenMismatch(t *testing.T) {
+	padder, err := NewBlockPadding(ISO97971M3, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, ISO97971M3, err)
+	}
+
+	var result bytes.Buffer
+	var writer *Writer
+	writer, err = padder.NewSizedWriter(&result, 20)
+	if err != nil {
+		t.Fatalf(`Could not create sized writer: %v`, err)
+	}
+
+	// Too much data.
+	_, err = writer.Write(makeTestSlice(21))
+	if !errors.Is(err, ErrDataLenMismatch) {
+		t.Fatalf(`Wrong error writing too much data: %v`, err)
+	}
+
+	// Too little data.
+	_, err = writer.Write(makeTestSlice(19))
+	if err != nil {
+		t.Fatalf(`Write failed: %v`, err)
+	}
+
+	err = writer.Close()
+	if !errors.Is(err, ErrDataLenMismatch) {
+		t.Fatalf(`Wrong error closing with too little data: %v`, err)
+	}
+
+	_, err = padder.NewSizedWriter(&result, -1)
+	if !errors.Is(err, ErrDataLenMismatch) {
+		t.Fatalf(`Wrong error creating sized writer with negative length: %v`, err)
+	}
+}
+
+func TestWriterDestinationError(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	writer, err := padder.NewWriter(errorWriter{})
+	if err != nil {
+		t.Fatalf(`Could not create writer: %v`, err)
+	}
+
+	// The first write is held back, so it does not reach the underlying writer.
+	_, err = writer.Write(makeTestSlice(5))
+	if err != nil {
+		t.Fatalf(`Write failed: %v`, err)
+	}
+
+	// The second write completes the held back block, which can not be written.
+	var n int
+	n, err = writer.Write(makeTestSlice(2 * testBlockSize))
+	if !errors.Is(err, errDestination) {
+		t.Fatalf(`Wrong error writing to failing writer: %v`, err)
+	}
+	if n != testBlockSize-5 {
+		t.Fatalf(`Write reported %d consumed bytes instead of %d`, n, testBlockSize-5)
+	}
+}
+
+func TestSizedWriterDestinationError(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	writer, err := padder.NewSizedWriter(errorWriter{}, 3*testBlockSize)
+	if err != nil {
+		t.Fatalf(`Could not create sized writer: %v`, err)
+	}
+
+	_, err = writer.Write(makeTestSlice(5))
+	if err != nil {
+		t.Fatalf(`Write failed: %v`, err)
+	}
+
+	// Only the bytes that have been consumed count as written.
+	var n int
+	n, err = writer.Write(makeTestSlice(2 * testBlockSize))
+	if !errors.Is(err, errDestination) {
+		t.Fatalf(`Wrong error writing to failing writer: %v`, err)
+	}
+	if writer.written != 5+n {
+		t.Fatalf(`Writer counts %d written bytes instead of %d`, writer.written, 5+n)
+	}
+}
+
+func TestCryptWriterWrongBlockSize(t *testing.T) {
+	padder, err := NewBlockPadding(PKCS7, testBlockSize)
+	if err != nil {
+		t.Fatalf(`Error creating BlockPad with pad type %d: %v`, PKCS7, err)
+	}
+
+	desCipher, err := des.NewCipher(makeTestSlice(8))
+	if err != nil {
+		t.Fatalf(`Could not create DES cipher: %v`, err)
+	}
+
+	_, err = padder.NewCryptWriter(&bytes.Buffer{}, cipher.NewCBCEncrypter(desCipher, makeTestSlice(8)))
+	if !errors.Is(err, ErrInvalidBlockMode) {
+		t.Fatalf(`Wrong error creating crypt writer with wrong block size: %v`, err)
+	}
+}
+
+// ******** Private types ********
+
+// errorWriter is an [io.Writer] that always fails.
+type errorWriter struct{}
+
+// errDestination is the error returned by errorWriter.
+var errDestination = errors.New(`destination failed`)
+
+// Write always returns errDestination.
+func (errorWriter) Write([]byte) (int, error) {
+	return 0, errDestination
+}
+
+// ******** Private functions ********
+
+// writeInRandomPieces writes data in pieces of random length to a writer and closes it.
+func writeInRandomPieces(t *testing.T, writer *Writer, data []byte) {
+	for len(data) > 0 {
+		pieceLen := mrand.Intn(len(data)) + 1
+
+		n, err := writer.Write(data[:pieceLen])
+		if err != nil {
+			t.Fatalf(`Write failed: %v`, err)
+		}
+		if n != pieceLen {
+			t.Fatalf(`Write wrote %d bytes instead of %d`, n, pieceLen)
+		}
+
+		data = data[pieceLen:]
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatalf(`Close failed: %v`, err)
+	}
+}